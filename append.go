@@ -0,0 +1,166 @@
+package csv
+
+import (
+	"bufio"
+	stdcsv "encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AppendOpts configures [NewSafeAppender].
+type AppendOpts struct {
+	// Header is the header expected at the top of the file. It is written
+	// when the file is empty, unless it is empty too.
+	Header []string
+	// CaseInsensitive compares header names regardless of case.
+	CaseInsensitive bool
+	// Comma is the field delimiter of the file (',' when 0).
+	Comma rune
+}
+
+// A HeaderMismatchError is returned by [NewSafeAppender] when the header of
+// the file differs from the expected one.
+type HeaderMismatchError struct {
+	Expected []string
+	Actual   []string
+	Column   int // Index of the first differing column
+}
+
+func (e *HeaderMismatchError) Error() string {
+	if e.Column < len(e.Expected) && e.Column < len(e.Actual) {
+		return fmt.Sprintf("csv: header mismatch at column %d: expected %q, got %q", e.Column, e.Expected[e.Column], e.Actual[e.Column])
+	}
+	return fmt.Sprintf("csv: header mismatch: expected %d columns, got %d", len(e.Expected), len(e.Actual))
+}
+
+// NewSafeAppender returns a SafeWriter appending records to f.
+//
+// If f is empty, the expected header is written first. Otherwise the header
+// of f is read and compared to the expected one, so that records are never
+// appended under misaligned columns. Names are compared ignoring surrounding
+// spaces, which escaping and padding may have added.
+//
+// [SafeWriter.UseCRLF] is set to match the line terminator of the file, and
+// a missing terminator after the last record of the file is added.
+//
+// Row indexes reported by the returned writer are relative to the first
+// appended record.
+func NewSafeAppender(f io.ReadWriteSeeker, opts SafetyOpts, appendOpts AppendOpts) (*SafeWriter, error) {
+	comma := appendOpts.Comma
+	if comma == 0 {
+		comma = ','
+	}
+
+	crlf, err := usesCRLF(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	r := stdcsv.NewReader(f)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	actual, err := r.Read()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	w := NewSafeWriter(f, opts)
+	w.Comma = comma
+
+	if err == io.EOF {
+		if len(appendOpts.Header) > 0 {
+			if err := w.WriteHeader(appendOpts.Header); err != nil {
+				return nil, err
+			}
+		}
+		return w, nil
+	}
+
+	if len(actual) > 0 {
+		actual[0] = strings.TrimPrefix(actual[0], "\ufeff")
+	}
+	if column := headerMismatch(opts, appendOpts.Header, actual, appendOpts.CaseInsensitive); column >= 0 {
+		return nil, &HeaderMismatchError{Expected: appendOpts.Header, Actual: actual, Column: column}
+	}
+
+	terminated, err := endsWithNewline(f)
+	if err != nil {
+		return nil, err
+	}
+
+	w.UseCRLF = crlf
+	if !terminated {
+		// The last record of the file is not terminated: appending right
+		// away would merge it with the first appended record.
+		if _, err := w.w.WriteString(w.lineEnd()); err != nil {
+			return nil, err
+		}
+	}
+
+	w.input = append([]string{}, appendOpts.Header...)
+	w.header = w.input
+	return w, nil
+}
+
+// usesCRLF reports whether the first line of f ends with \r\n.
+func usesCRLF(f io.ReadSeeker) (bool, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return strings.HasSuffix(line, "\r\n"), nil
+}
+
+// endsWithNewline reports whether f ends with a line terminator, and leaves
+// the offset of f at its end.
+func endsWithNewline(f io.ReadSeeker) (bool, error) {
+	if _, err := f.Seek(-1, io.SeekEnd); err != nil {
+		return false, err
+	}
+
+	var last [1]byte
+	if _, err := io.ReadFull(f, last[:]); err != nil {
+		return false, err
+	}
+	return last[0] == '\n', nil
+}
+
+// headerMismatch returns the index of the first column differing between
+// both headers, or -1 when they hold the same names in the same order.
+func headerMismatch(opts SafetyOpts, expected []string, actual []string, caseInsensitive bool) int {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		if !sameHeaderName(opts, expected[i], actual[i], caseInsensitive) {
+			return i
+		}
+	}
+
+	if len(expected) != len(actual) {
+		if len(expected) < len(actual) {
+			return len(expected)
+		}
+		return len(actual)
+	}
+	return -1
+}
+
+// sameHeaderName reports whether both header names are the same, ignoring
+// surrounding spaces. A name also matches its sanitized form, as written to
+// a file by a [SafeWriter] using opts.
+func sameHeaderName(opts SafetyOpts, expected, actual string, caseInsensitive bool) bool {
+	equal := func(a, b string) bool {
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+		return a == b || caseInsensitive && strings.EqualFold(a, b)
+	}
+	return equal(expected, actual) || equal(opts.Sanitize(expected), actual) || equal(expected, opts.Sanitize(actual))
+}
@@ -0,0 +1,132 @@
+package csv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestFile(t *testing.T, content string) *os.File {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.csv")
+	must(os.WriteFile(path, []byte(content), 0o600))
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	must(err)
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func readTestFile(t *testing.T, f *os.File) string {
+	t.Helper()
+
+	b, err := os.ReadFile(f.Name())
+	must(err)
+	return string(b)
+}
+
+func TestNewSafeAppender(t *testing.T) {
+	is := assert.New(t)
+
+	// empty file
+	f := openTestFile(t, "")
+	w, err := NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"id", "=name"}})
+	is.NoError(err)
+	is.NoError(w.WriteAll([][]string{{"1", "-a"}}))
+	is.Equal("id,\" =name\"\n1,\" -a\"\n", readTestFile(t, f))
+
+	// existing file
+	w, err = NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"id", "=name"}})
+	is.NoError(err)
+	is.NoError(w.WriteAll([][]string{{"2", "b"}}))
+	is.Equal("id,\" =name\"\n1,\" -a\"\n2,b\n", readTestFile(t, f))
+	is.Equal([]string{"id", "=name"}, w.header)
+
+	// case-insensitive and custom delimiter, with a BOM
+	f = openTestFile(t, "\ufeffID;Name\n1;a\n")
+	_, err = NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"id", "name"}, Comma: ';'})
+	is.EqualError(err, `csv: header mismatch at column 0: expected "id", got "ID"`)
+
+	w, err = NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"id", "name"}, Comma: ';', CaseInsensitive: true})
+	is.NoError(err)
+	is.NoError(w.WriteAll([][]string{{"2", "b"}}))
+	is.Equal("\ufeffID;Name\n1;a\n2;b\n", readTestFile(t, f))
+
+	// empty file without header
+	f = openTestFile(t, "")
+	w, err = NewSafeAppender(f, EscapeAll, AppendOpts{})
+	is.NoError(err)
+	is.NoError(w.WriteAll([][]string{{"1", "a"}}))
+	is.Equal("1,a\n", readTestFile(t, f))
+}
+
+func TestNewSafeAppenderSanitizedHeader(t *testing.T) {
+	is := assert.New(t)
+
+	header := AppendOpts{Header: []string{"id", "=total"}}
+
+	for strategy, content := range map[Strategy]string{
+		StrategyPrefix: "id,'=total\n1,'-2\n",
+		StrategyStrip:  "id,total\n1,2\n",
+	} {
+		opts := EscapeAll
+		opts.Strategy = strategy
+		opts.EscapePrefix = "'"
+
+		f := openTestFile(t, "")
+		w, err := NewSafeAppender(f, opts, header)
+		is.NoError(err)
+		is.NoError(w.WriteAll([][]string{{"1", "-2"}}))
+
+		w, err = NewSafeAppender(f, opts, header)
+		is.NoError(err)
+		is.NoError(w.WriteAll([][]string{{"3", "4"}}))
+		is.Equal(content+"3,4\n", readTestFile(t, f))
+	}
+}
+
+func TestNewSafeAppenderMismatch(t *testing.T) {
+	is := assert.New(t)
+
+	f := openTestFile(t, "id,name\n1,a\n")
+
+	_, err := NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"name", "id"}})
+	is.Equal(&HeaderMismatchError{Expected: []string{"name", "id"}, Actual: []string{"id", "name"}, Column: 0}, err)
+
+	_, err = NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"id", "name", "email"}})
+	is.EqualError(err, "csv: header mismatch: expected 3 columns, got 2")
+
+	_, err = NewSafeAppender(f, EscapeAll, AppendOpts{Header: []string{"id"}})
+	is.EqualError(err, "csv: header mismatch: expected 1 columns, got 2")
+
+	is.Equal("id,name\n1,a\n", readTestFile(t, f))
+}
+
+func TestNewSafeAppenderLineEndings(t *testing.T) {
+	is := assert.New(t)
+
+	header := AppendOpts{Header: []string{"id", "name"}}
+
+	f := openTestFile(t, "id,name\r\n1,a\r\n")
+	w, err := NewSafeAppender(f, EscapeAll, header)
+	is.NoError(err)
+	is.True(w.UseCRLF)
+	is.NoError(w.WriteAll([][]string{{"2", "b"}}))
+	is.Equal("id,name\r\n1,a\r\n2,b\r\n", readTestFile(t, f))
+
+	f = openTestFile(t, "id,name\r\n1,a")
+	w, err = NewSafeAppender(f, EscapeAll, header)
+	is.NoError(err)
+	is.NoError(w.WriteAll([][]string{{"2", "b"}}))
+	is.Equal("id,name\r\n1,a\r\n2,b\r\n", readTestFile(t, f))
+
+	f = openTestFile(t, "id,name")
+	w, err = NewSafeAppender(f, EscapeAll, header)
+	is.NoError(err)
+	is.False(w.UseCRLF)
+	is.NoError(w.WriteAll([][]string{{"2", "b"}}))
+	is.Equal("id,name\n2,b\n", readTestFile(t, f))
+}
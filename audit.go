@@ -0,0 +1,28 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// An AuditRecord reports a record written by a [SafeWriter] to its Audit
+// function. Headers and trailers are not reported.
+type AuditRecord struct {
+	Row       int64  // Index of the record in the output, starting at 0
+	Sanitized int64  // Number of fields altered by sanitization
+	Key       string // Idempotency key, when [SafeWriter.IdempotencyKeys] is set
+}
+
+// rowKey returns the idempotency key of an encoded record: the first 128
+// bits of its SHA-256, hex-encoded.
+func rowKey(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:16])
+}
+
+// audit reports the record just written to [SafeWriter.Audit].
+func (w *SafeWriter) audit() {
+	if w.Audit != nil {
+		w.Audit(AuditRecord{Row: w.stats.Records - 1, Sanitized: w.lastSanitized, Key: w.lastKey})
+	}
+}
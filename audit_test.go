@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeWriterAudit(t *testing.T) {
+	is := assert.New(t)
+
+	key := func(line string) string {
+		sum := sha256.Sum256([]byte(line))
+		return hex.EncodeToString(sum[:16])
+	}
+
+	var audit []AuditRecord
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.UseCRLF = true
+	w.IdempotencyKeys = true
+	w.Audit = func(r AuditRecord) { audit = append(audit, r) }
+	w.Columns = []ColumnOpts{{Required: true}}
+
+	is.NoError(w.WriteHeader([]string{"id", "name"}))
+	is.NoError(w.Write([]string{"1", "=a"}))
+	is.Error(w.Write([]string{"", "b"}))
+	is.NoError(w.WriteMap(map[string]string{"id": "2", "name": "b"}))
+	is.NoError(w.WriteAllParallel([][]string{{"1", "=a"}}, ParallelOpts{}))
+	is.NoError(w.WriteTrailer([]string{"TRL"}))
+
+	is.Equal([]AuditRecord{
+		{Row: 1, Sanitized: 1, Key: key(`1," =a"`)},
+		{Row: 2, Key: key("2,b")},
+		{Row: 3, Sanitized: 1, Key: key(`1," =a"`)},
+	}, audit)
+
+	audit = nil
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Audit = func(r AuditRecord) { audit = append(audit, r) }
+	is.NoError(w.WriteCells([]Cell{StringCell("a")}))
+	is.Equal([]AuditRecord{{Row: 0}}, audit)
+}
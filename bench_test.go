@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// benchmarkShapes are the data shapes used to measure the cost of each
+// safety option.
+var benchmarkShapes = []struct {
+	name   string
+	record []string
+}{
+	{"plain", []string{"abc", "def", "ghi", "jkl"}},
+	{"formulas", []string{"=A1", "+1", "-1", "@SUM(A1:A2)"}},
+	{"quoted", []string{`say "hi"`, "a,b", "multi\nline", "\tindent"}},
+	{"numbers", []string{"12356", "4111111111111111", "3.14", "-42"}},
+	{"long", []string{strings.Repeat("lorem ipsum ", 20), strings.Repeat("x", 240), "abc", "def"}},
+}
+
+var benchmarkOpts = []struct {
+	name string
+	opts SafetyOpts
+}{
+	{"None", SafetyOpts{}},
+	{"ForceDoubleQuotes", SafetyOpts{ForceDoubleQuotes: true}},
+	{"EscapeCharEqual", SafetyOpts{EscapeCharEqual: true}},
+	{"EscapeCharPlus", SafetyOpts{EscapeCharPlus: true}},
+	{"EscapeCharMinus", SafetyOpts{EscapeCharMinus: true}},
+	{"EscapeCharAt", SafetyOpts{EscapeCharAt: true}},
+	{"EscapeCharTab", SafetyOpts{EscapeCharTab: true}},
+	{"EscapeCharLF", SafetyOpts{EscapeCharLF: true}},
+	{"EscapeCharCR", SafetyOpts{EscapeCharCR: true}},
+	{"EscapeCharPipe", SafetyOpts{EscapeCharPipe: true}},
+	{"EscapeCharPercent", SafetyOpts{EscapeCharPercent: true}},
+	{"LongNumberDigits", SafetyOpts{LongNumberDigits: 12}},
+	{"EscapeAll", EscapeAll},
+	{"FullSafety", FullSafety},
+}
+
+// BenchmarkSafetyOpts measures the cost of each safety option, for every
+// data shape. Run it with `make bench-safety`.
+func BenchmarkSafetyOpts(b *testing.B) {
+	for _, shape := range benchmarkShapes {
+		size := 0
+		for _, field := range shape.record {
+			size += len(field)
+		}
+
+		for _, opt := range benchmarkOpts {
+			b.Run(shape.name+"/"+opt.name, func(b *testing.B) {
+				w := NewSafeWriter(io.Discard, opt.opts)
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					if err := w.Write(shape.record); err != nil {
+						b.Fatal(err)
+					}
+				}
+				w.Flush()
+			})
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"encoding/base64"
+	"unicode/utf8"
+)
+
+// BinaryPolicy selects how a [SafeWriter] handles fields holding binary
+// data, such as a BLOB column that sneaked into the query of an export.
+type BinaryPolicy int
+
+const (
+	// BinaryAllow writes binary fields as is.
+	BinaryAllow BinaryPolicy = iota
+	// BinaryReject fails the record with [ErrBinaryField].
+	BinaryReject
+	// BinaryBase64 writes binary fields encoded with standard base64,
+	// prefixed with "base64:".
+	BinaryBase64
+)
+
+// binaryRatio is the share of control and invalid UTF-8 bytes above which
+// a field is considered binary.
+const binaryRatio = 0.1
+
+// isBinary reports whether field clearly holds binary data: it contains a
+// NUL byte, or more than 10% of its bytes are control characters other
+// than tabs and line terminators, or are not valid UTF-8.
+func isBinary(field string) bool {
+	suspect := 0
+	for i := 0; i < len(field); {
+		c := field[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == 0:
+				return true
+			case c < 0x20 && c != '\t' && c != '\n' && c != '\r', c == 0x7f:
+				suspect++
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(field[i:])
+		if r == utf8.RuneError && size == 1 {
+			suspect++
+		}
+		i += size
+	}
+	return float64(suspect) > binaryRatio*float64(len(field))
+}
+
+// apply applies the policy to field, reporting binary fields rejected by
+// [BinaryReject].
+func (p BinaryPolicy) apply(field string) (string, error) {
+	if p == BinaryAllow || !isBinary(field) {
+		return field, nil
+	}
+	if p == BinaryReject {
+		return field, ErrBinaryField
+	}
+	return "base64:" + base64.StdEncoding.EncodeToString([]byte(field)), nil
+}
@@ -0,0 +1,50 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBinary(t *testing.T) {
+	is := assert.New(t)
+
+	is.False(isBinary(""))
+	is.False(isBinary("plain text, with\ttabs\r\nand lines"))
+	is.False(isBinary("café ☕"))
+	is.False(isBinary("bell\a in a long enough sentence"))
+	is.True(isBinary("a\x00b"))
+	is.True(isBinary("\x89PNG\r\n\x1a\n"))
+	is.True(isBinary("\xff\xfe\xfdabc"))
+}
+
+func TestBinaryPolicy(t *testing.T) {
+	is := assert.New(t)
+
+	record := []string{"1", "\x89PNG\r\n\x1a\n", "ok"}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Binary = BinaryBase64
+	is.NoError(w.Write(record))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("1,base64:iVBORw0KGgo=,ok\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Binary = BinaryReject
+	err := w.Write(record)
+	is.ErrorIs(err, ErrBinaryField)
+	is.EqualError(err, "csv: record 0, column 1: field holds binary data")
+	is.NoError(w.Write([]string{"2", "text", "ok"}))
+	w.Flush()
+	is.Equal("2,text,ok\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, SafetyOpts{})
+	is.NoError(w.Write([]string{"a\x00b"}))
+	w.Flush()
+	is.Equal("a\x00b\n", buff.String())
+}
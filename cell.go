@@ -0,0 +1,207 @@
+package csv
+
+import (
+	"math"
+	"strconv"
+)
+
+// A Cell is a single field written by [SafeWriter.WriteCells]. Unlike the
+// plain strings given to [SafeWriter.Write], a Cell carries its own
+// sanitization policy.
+type Cell interface {
+	// Value returns the text of the cell, before escaping and quoting.
+	Value() string
+	// Policy returns how the cell must be encoded.
+	Policy() CellPolicy
+}
+
+// CellPolicy describes how [SafeWriter.WriteCells] encodes a [Cell].
+type CellPolicy struct {
+	// Trusted disables the escaping configured by [SafetyOpts]. Quoting
+	// and the guard of [SafetyOpts.LongNumberDigits] still apply, so the
+	// output remains valid CSV and long numbers remain text.
+	Trusted bool
+	// Raw, with Trusted, also disables the guard of
+	// [SafetyOpts.LongNumberDigits]: the value is written as is.
+	Raw bool
+	// Quote controls whether the cell is enclosed in double quotes.
+	Quote QuoteMode
+}
+
+// QuoteMode controls whether a field is enclosed in double quotes.
+type QuoteMode int
+
+const (
+	// QuoteAuto quotes the field when required, or when
+	// [SafetyOpts.ForceDoubleQuotes] is set.
+	QuoteAuto QuoteMode = iota
+	// QuoteAlways quotes the field, even when empty.
+	QuoteAlways
+	// QuoteMinimal quotes the field only when required to produce valid CSV,
+	// ignoring [SafetyOpts.ForceDoubleQuotes].
+	QuoteMinimal
+)
+
+// StringCell is a regular field, sanitized like any field given to
+// [SafeWriter.Write].
+type StringCell string
+
+// Value implements [Cell].
+func (c StringCell) Value() string { return string(c) }
+
+// Policy implements [Cell].
+func (c StringCell) Policy() CellPolicy { return CellPolicy{} }
+
+// Formula is an intentional spreadsheet formula, such as `=SUM(A1:A3)`.
+//
+// A Formula is never sanitized, so it must only be built from trusted
+// values: never concatenate user input into a Formula.
+type Formula string
+
+// Value implements [Cell].
+func (c Formula) Value() string { return string(c) }
+
+// Policy implements [Cell].
+func (c Formula) Policy() CellPolicy { return CellPolicy{Trusted: true} }
+
+// NumberCell is a numeric field. Numbers cannot carry a formula, so a
+// finite NumberCell is never escaped: -42 is written as is, and only long
+// digit strings get the guard of [SafetyOpts.LongNumberDigits]. Infinities
+// and NaN, written as +Inf, -Inf and NaN, are sanitized like strings.
+type NumberCell float64
+
+// Value implements [Cell].
+func (c NumberCell) Value() string { return strconv.FormatFloat(float64(c), 'f', -1, 64) }
+
+// Policy implements [Cell].
+func (c NumberCell) Policy() CellPolicy { return CellPolicy{Trusted: isFinite(float64(c))} }
+
+// Float32Cell is a float32 field, written with the shortest representation
+// of a float32, such as 0.1 instead of 0.10000000149011612. Like
+// [NumberCell], it is never escaped unless it is not finite.
+type Float32Cell float32
+
+// Value implements [Cell].
+func (c Float32Cell) Value() string { return strconv.FormatFloat(float64(c), 'f', -1, 32) }
+
+// Policy implements [Cell].
+func (c Float32Cell) Policy() CellPolicy { return CellPolicy{Trusted: isFinite(float64(c))} }
+
+// IntCell is an integer field. Like [NumberCell], it is never escaped, and
+// it is written with every digit, even beyond the precision of a float64.
+type IntCell int64
+
+// Value implements [Cell].
+func (c IntCell) Value() string { return strconv.FormatInt(int64(c), 10) }
+
+// Policy implements [Cell].
+func (c IntCell) Policy() CellPolicy { return CellPolicy{Trusted: true} }
+
+// UintCell is an unsigned integer field, written like [IntCell].
+type UintCell uint64
+
+// Value implements [Cell].
+func (c UintCell) Value() string { return strconv.FormatUint(uint64(c), 10) }
+
+// Policy implements [Cell].
+func (c UintCell) Policy() CellPolicy { return CellPolicy{Trusted: true} }
+
+// isFinite reports whether f is neither an infinity nor NaN.
+func isFinite(f float64) bool {
+	return !math.IsInf(f, 0) && !math.IsNaN(f)
+}
+
+// BoolCell is a boolean field, rendered with [SafeWriter.BoolFormat] or the
+// BoolFormat of its column.
+type BoolCell bool
+
+// Value implements [Cell].
+func (c BoolCell) Value() string { return strconv.FormatBool(bool(c)) }
+
+// Policy implements [Cell].
+func (c BoolCell) Policy() CellPolicy { return CellPolicy{Trusted: true} }
+
+// RawCell is a field written without any sanitization. It must only be used
+// for values that are known to be safe.
+type RawCell string
+
+// Value implements [Cell].
+func (c RawCell) Value() string { return string(c) }
+
+// Policy implements [Cell].
+func (c RawCell) Policy() CellPolicy { return CellPolicy{Trusted: true, Raw: true} }
+
+// Raw returns field as a [RawCell], bypassing all sanitization. Calls to Raw
+// are meant to be easy to find when auditing a codebase.
+func Raw(field string) RawCell {
+	return RawCell(field)
+}
+
+type quotedCell struct {
+	Cell
+	quote QuoteMode
+}
+
+func (c quotedCell) Policy() CellPolicy {
+	policy := c.Cell.Policy()
+	policy.Quote = c.quote
+	return policy
+}
+
+// WithQuote returns a copy of cell using the given quote mode.
+func WithQuote(cell Cell, quote QuoteMode) Cell {
+	return quotedCell{Cell: cell, quote: quote}
+}
+
+// WriteCells writes a single CSV record made of cells, applying the policy
+// of each cell. As with [SafeWriter.Write], writes are buffered.
+func (w *SafeWriter) WriteCells(cells []Cell) error {
+	if !validDelim(w.Comma) {
+		return errInvalidDelim
+	}
+	if w.aborted != nil {
+		return w.aborted
+	}
+
+	w.fields = w.fields[:0]
+	for n, cell := range w.projectCells(cells) {
+		prepared, err := w.prepareField(n, w.cellValue(n, cell), cell.Policy())
+		if err != nil {
+			return w.reject(cellValues(cells), err)
+		}
+		w.fields = append(w.fields, prepared)
+	}
+
+	if len(w.computed) > 0 {
+		record := cellValues(cells)
+		if err := w.prepareComputed(record); err != nil {
+			return w.reject(record, err)
+		}
+	}
+	if err := w.writeFields(); err != nil {
+		return err
+	}
+	w.audit()
+	return nil
+}
+
+// cellValues returns the values of cells.
+func cellValues(cells []Cell) []string {
+	values := make([]string, len(cells))
+	for i, cell := range cells {
+		values[i] = cell.Value()
+	}
+	return values
+}
+
+// cellValue returns the text of the cell at position n, rendering booleans
+// with the format configured on the writer.
+func (w *SafeWriter) cellValue(n int, cell Cell) string {
+	switch c := cell.(type) {
+	case BoolCell:
+		return w.boolFormat(n).render(bool(c))
+	case quotedCell:
+		return w.cellValue(n, c.Cell)
+	}
+	return cell.Value()
+}
@@ -0,0 +1,64 @@
+package csv
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCells(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.NoError(w.WriteCells([]Cell{StringCell("total"), StringCell("=A1"), Formula("=SUM(A1:A3)")}))
+	is.NoError(w.WriteCells([]Cell{StringCell("a,b"), Formula(`=CONCAT("a", "b")`)}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("total,\" =A1\",=SUM(A1:A3)\n\"a,b\",\"=CONCAT(\"\"a\"\", \"\"b\"\")\"\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, FullSafety)
+	is.NoError(w.WriteCells([]Cell{Formula("=SUM(A1:A3)")}))
+	w.Flush()
+	is.Equal("\"=SUM(A1:A3)\"\n", buff.String())
+
+	w.Comma = '"'
+	is.ErrorIs(w.WriteCells([]Cell{StringCell("a")}), errInvalidDelim)
+}
+
+func TestTypedCells(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.NoError(w.WriteCells([]Cell{NumberCell(-42), NumberCell(3.14), BoolCell(true), RawCell("-raw"), Raw("=raw"), StringCell("-str")}))
+	w.Flush()
+	is.Equal("-42,3.14,true,-raw,=raw,\" -str\"\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	is.NoError(w.WriteCells([]Cell{NumberCell(math.Inf(1)), NumberCell(math.Inf(-1)), NumberCell(math.NaN())}))
+	is.NoError(w.WriteCells([]Cell{Float32Cell(-0.1), Float32Cell(math.Inf(-1))}))
+	w.Flush()
+	is.Equal("\" +Inf\",\" -Inf\",NaN\n-0.1,\" -Inf\"\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, FullSafety)
+	is.NoError(w.WriteCells([]Cell{
+		NumberCell(42),
+		WithQuote(NumberCell(42), QuoteMinimal),
+		WithQuote(StringCell("-a,b"), QuoteMinimal),
+		WithQuote(StringCell(""), QuoteAlways),
+		StringCell(""),
+	}))
+	w.Flush()
+	is.Equal("\"42\",42,\" -a,b\",\"\",\n", buff.String())
+
+	is.Equal(CellPolicy{Trusted: true, Raw: true, Quote: QuoteAlways}, WithQuote(RawCell("x"), QuoteAlways).Policy())
+	is.Equal("x", WithQuote(RawCell("x"), QuoteAlways).Value())
+}
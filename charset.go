@@ -0,0 +1,33 @@
+package csv
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252 to their code
+// point. Undefined bytes map to the matching C1 control character, as
+// specified by the WHATWG encoding standard. Other bytes match Latin-1.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+// decodeWindows1252 converts s from Windows-1252 to UTF-8.
+func decodeWindows1252(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + len(s)/2)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < utf8.RuneSelf:
+			b.WriteByte(c)
+		case c < 0xA0:
+			b.WriteRune(windows1252[c-0x80])
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,221 @@
+package csv
+
+import "io"
+
+// A ChunkedWriter splits records into several outputs holding at most a
+// given number of records each, such as files small enough to be opened by
+// spreadsheet software. The header, if any, is repeated at the top of every
+// output.
+//
+// The exported fields apply to every output, and must be set before the
+// first write.
+type ChunkedWriter struct {
+	Comma   rune // Field delimiter (set to ',' by NewChunkedWriter)
+	UseCRLF bool // True to use \r\n as the line terminator
+
+	// Metadata is attached to errors, see [SafeWriter.Metadata].
+	Metadata map[string]string
+
+	rows   int
+	opts   SafetyOpts
+	create func(index int) (io.WriteCloser, error)
+	header []string
+	out    io.WriteCloser
+	w      *SafeWriter
+	count  int // Records written to the current output
+	chunks int
+}
+
+// NewChunkedWriter returns a new ChunkedWriter writing at most rows records,
+// header excluded, to each output. create is called to open the output of
+// the chunk at index, starting at 0, when its first record is written.
+func NewChunkedWriter(rows int, opts SafetyOpts, create func(index int) (io.WriteCloser, error)) *ChunkedWriter {
+	return &ChunkedWriter{
+		Comma:  ',',
+		rows:   rows,
+		opts:   opts,
+		create: create,
+	}
+}
+
+// WriteHeader sets the header written at the top of every output. It must
+// be called before the first record is written.
+func (c *ChunkedWriter) WriteHeader(header []string) error {
+	if c.chunks > 0 {
+		return errHeaderAfterRecords
+	}
+	c.header = append([]string{}, header...)
+	return nil
+}
+
+// Write writes a single record to the current output, creating a new one
+// when it is full.
+func (c *ChunkedWriter) Write(record []string) error {
+	if c.w == nil || c.count >= c.rows && c.rows > 0 {
+		if err := c.next(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	c.count++
+	return nil
+}
+
+// next closes the current output and creates the next one.
+func (c *ChunkedWriter) next() error {
+	if err := c.closeChunk(); err != nil {
+		return err
+	}
+
+	out, err := c.create(c.chunks)
+	if err != nil {
+		return err
+	}
+	c.chunks++
+
+	c.out, c.count = out, 0
+	c.w = NewSafeWriter(out, c.opts)
+	c.w.Comma = c.Comma
+	c.w.UseCRLF = c.UseCRLF
+	c.w.Metadata = c.Metadata
+
+	if c.header != nil {
+		return c.w.WriteHeader(c.header)
+	}
+	return nil
+}
+
+// closeChunk flushes and closes the current output, if any.
+func (c *ChunkedWriter) closeChunk() error {
+	if c.w == nil {
+		return nil
+	}
+
+	c.w.Flush()
+	err := c.w.Error()
+	if cerr := c.out.Close(); err == nil {
+		err = cerr
+	}
+	c.w, c.out = nil, nil
+	return err
+}
+
+// Close flushes and closes the current output.
+func (c *ChunkedWriter) Close() error {
+	return c.closeChunk()
+}
+
+// Chunks returns the number of outputs created so far.
+func (c *ChunkedWriter) Chunks() int {
+	return c.chunks
+}
+
+// MergeOpts configures [Merge].
+type MergeOpts struct {
+	// Comma is the field delimiter of the inputs and of the output
+	// (',' when 0).
+	Comma rune
+	// CaseInsensitive compares header names regardless of case.
+	CaseInsensitive bool
+}
+
+// Merge writes the records of every source to dst, sanitized according to
+// opts, under a single header. Every source must start with a header holding
+// the same names as the first one, possibly in another order: such records
+// are reordered to match the first header. Otherwise a [HeaderMismatchError]
+// is returned. Merge returns the number of records written, header excluded.
+func Merge(dst io.Writer, srcs []io.Reader, opts SafetyOpts, mergeOpts MergeOpts) (int64, error) {
+	comma := mergeOpts.Comma
+	if comma == 0 {
+		comma = ','
+	}
+
+	w := NewSafeWriter(dst, opts)
+	w.Comma = comma
+
+	var header []string
+	var rows int64
+	for _, src := range srcs {
+		r := newLenientReader(src, comma)
+
+		actual, err := r.Read()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return rows, err
+		}
+
+		if header == nil {
+			header = actual
+			if err := w.WriteHeader(header); err != nil {
+				return rows, err
+			}
+		}
+
+		positions, err := reorder(opts, header, actual, mergeOpts.CaseInsensitive)
+		if err != nil {
+			return rows, err
+		}
+
+		for {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return rows, err
+			}
+
+			if positions != nil {
+				reordered := make([]string, len(positions))
+				for i, position := range positions {
+					if position < len(record) {
+						reordered[i] = record[position]
+					}
+				}
+				record = reordered
+			}
+
+			if err := w.Write(record); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+
+	w.Flush()
+	return rows, w.Error()
+}
+
+// reorder returns the position in actual of every name of expected, or nil
+// when both headers are the same.
+func reorder(opts SafetyOpts, expected []string, actual []string, caseInsensitive bool) ([]int, error) {
+	column := headerMismatch(opts, expected, actual, caseInsensitive)
+	if column < 0 {
+		return nil, nil
+	}
+
+	mismatch := &HeaderMismatchError{Expected: expected, Actual: actual, Column: column}
+	if len(expected) != len(actual) {
+		return nil, mismatch
+	}
+
+	positions := make([]int, len(expected))
+	for i, name := range expected {
+		positions[i] = -1
+		for j, other := range actual {
+			if sameHeaderName(opts, name, other, caseInsensitive) {
+				positions[i] = j
+				break
+			}
+		}
+		if positions[i] < 0 {
+			return nil, mismatch
+		}
+	}
+	return positions, nil
+}
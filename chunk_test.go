@@ -0,0 +1,86 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closingBuilder struct {
+	strings.Builder
+	closed bool
+}
+
+func (b *closingBuilder) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestChunkedWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var chunks []*closingBuilder
+	w := NewChunkedWriter(2, EscapeAll, func(index int) (io.WriteCloser, error) {
+		is.Equal(len(chunks), index)
+		chunks = append(chunks, &closingBuilder{})
+		return chunks[index], nil
+	})
+	w.Comma = ';'
+
+	is.NoError(w.WriteHeader([]string{"id", "name"}))
+	for _, record := range [][]string{{"1", "=a"}, {"2", "b"}, {"3", "c"}} {
+		is.NoError(w.Write(record))
+	}
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errHeaderAfterRecords)
+	is.NoError(w.Close())
+
+	is.Equal(2, w.Chunks())
+	is.Len(chunks, 2)
+	is.Equal("id;name\n1;\" =a\"\n2;b\n", chunks[0].String())
+	is.Equal("id;name\n3;c\n", chunks[1].String())
+	is.True(chunks[0].closed)
+	is.True(chunks[1].closed)
+
+	errCreate := errors.New("cannot create")
+	w = NewChunkedWriter(1, EscapeAll, func(index int) (io.WriteCloser, error) {
+		return nil, errCreate
+	})
+	is.ErrorIs(w.Write([]string{"a"}), errCreate)
+	is.NoError(w.Close())
+}
+
+func TestMerge(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	n, err := Merge(&buff, []io.Reader{
+		strings.NewReader("id,name\n1,=a\n"),
+		strings.NewReader(""),
+		strings.NewReader("NAME,ID\nb,2\nc\n"),
+	}, EscapeAll, MergeOpts{CaseInsensitive: true})
+	is.NoError(err)
+	is.EqualValues(3, n)
+	is.Equal("id,name\n1,\" =a\"\n2,b\n,c\n", buff.String())
+
+	buff.Reset()
+	n, err = Merge(&buff, []io.Reader{
+		strings.NewReader("id,name\n1,a\n"),
+		strings.NewReader("id,email\n2,b\n"),
+	}, EscapeAll, MergeOpts{})
+	is.EqualValues(1, n)
+	is.Equal(&HeaderMismatchError{Expected: []string{"id", "name"}, Actual: []string{"id", "email"}, Column: 1}, err)
+
+	opts := EscapeAll
+	opts.EscapePrefix = "'"
+	buff.Reset()
+	n, err = Merge(&buff, []io.Reader{
+		strings.NewReader("id,=total\n1,2\n"),
+		strings.NewReader("'=total,id\n4,3\n"),
+	}, opts, MergeOpts{})
+	is.NoError(err)
+	is.EqualValues(2, n)
+	is.Equal("id,'=total\n1,2\n3,4\n", buff.String())
+}
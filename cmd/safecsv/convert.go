@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	csv "github.com/samber/go-safe-csv-writer"
+)
+
+// convert implements `safecsv convert --from ';' --to ',' [file]`, writing
+// the sanitized conversion of file, or of the standard input, to the
+// standard output.
+func convert(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	from := flags.String("from", ",", "field `delimiter` of the input (\\t for tabs)")
+	to := flags.String("to", ",", "field `delimiter` of the output (\\t for tabs)")
+	quoteAll := flags.Bool("quote-all", false, "quote every field of the output")
+	crlf := flags.Bool("crlf", false, "use \\r\\n as the line terminator of the output")
+	safety := flags.String("safety", "escape-all", "safety `preset`: none, escape-all or full-safety")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "usage: safecsv convert [flags] [file.csv]")
+		return exitError
+	}
+
+	opts, err := parseSafety(*safety)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+	opts.ForceDoubleQuotes = opts.ForceDoubleQuotes || *quoteAll
+
+	copyOpts := csv.CopyOpts{UseCRLF: *crlf}
+	if copyOpts.Comma, err = parseComma(*from); err == nil {
+		copyOpts.OutputComma, err = parseComma(*to)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+
+	src := stdin
+	if flags.NArg() == 1 {
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintf(stderr, "safecsv: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		src = f
+	}
+
+	if _, err := csv.SafeCopy(stdout, src, opts, copyOpts); err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	is := assert.New(t)
+
+	code, stdout, stderr := runCommand("id;name\n1;=A1\n2;\"a,b\"\n", "convert", "--from", ";", "--to", ",")
+	is.Equal(exitOK, code)
+	is.Empty(stderr)
+	is.Equal("id,name\n1,\" =A1\"\n2,\"a,b\"\n", stdout)
+
+	file := writeFile(t, "input.tsv", "id\tname\n1\t@x\n")
+	code, stdout, _ = runCommand("", "convert", "--from", `\t`, "--to", ";", "--quote-all", "--crlf", file)
+	is.Equal(exitOK, code)
+	is.Equal("\"id\";\"name\"\r\n\"1\";\" @x\"\r\n", stdout)
+
+	code, stdout, _ = runCommand("a,=b\n", "convert", "--safety", "none")
+	is.Equal(exitOK, code)
+	is.Equal("a,=b\n", stdout)
+
+	code, _, stderr = runCommand("", "convert", "--to", ";;")
+	is.Equal(exitError, code)
+	is.Contains(stderr, `invalid delimiter ";;"`)
+}
@@ -0,0 +1,57 @@
+// Command safecsv checks and hardens CSV files with the sanitization of
+// github.com/samber/go-safe-csv-writer.
+//
+// Usage:
+//
+//	safecsv <command> [flags] [files]
+//
+// The commands are:
+//
+//	convert   convert a file between dialects, sanitizing it
+//	merge     merge files under a single header
+//	redact    mask, hash or remove the values of columns
+//	split     split a file into files of a given number of records
+//	validate  check files against a profile, reporting findings as JSON lines
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes of the commands.
+const (
+	exitOK       = 0
+	exitFindings = 1 // The input does not comply
+	exitError    = 2 // Invalid usage, or I/O error
+)
+
+// A command runs a subcommand with its arguments.
+type command func(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int
+
+var commands = map[string]command{
+	"convert":  convert,
+	"merge":    merge,
+	"redact":   redact,
+	"split":    split,
+	"validate": validate,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: safecsv <command> [flags] [files]")
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "safecsv: unknown command %q\n", args[0])
+		return exitError
+	}
+	return cmd(args[1:], stdin, stdout, stderr)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFile writes content to a temporary file and returns its path.
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runCommand runs safecsv with args and returns its exit code and outputs.
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	is := assert.New(t)
+
+	code, _, stderr := runCommand("")
+	is.Equal(exitError, code)
+	is.Contains(stderr, "usage")
+
+	code, _, stderr = runCommand("", "unknown")
+	is.Equal(exitError, code)
+	is.Equal("safecsv: unknown command \"unknown\"\n", stderr)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	csv "github.com/samber/go-safe-csv-writer"
+)
+
+// merge implements `safecsv merge part*.csv`, writing the records of every
+// file to the standard output under a single header. Files whose header
+// holds the same names in another order are reordered.
+func merge(args []string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	comma := flags.String("comma", ",", "field `delimiter` (\\t for tabs)")
+	ignoreCase := flags.Bool("ignore-case", false, "compare header names regardless of case")
+	safety := flags.String("safety", "escape-all", "safety `preset`: none, escape-all or full-safety")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: safecsv merge [flags] file.csv...")
+		return exitError
+	}
+
+	opts, err := parseSafety(*safety)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+	delim, err := parseComma(*comma)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+
+	var srcs []io.Reader
+	for _, name := range flags.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "safecsv: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		srcs = append(srcs, f)
+	}
+
+	if _, err := csv.Merge(stdout, srcs, opts, csv.MergeOpts{Comma: delim, CaseInsensitive: *ignoreCase}); err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
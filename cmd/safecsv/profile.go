@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	csv "github.com/samber/go-safe-csv-writer"
+	"gopkg.in/yaml.v3"
+)
+
+// A Profile declares the dialect, schema and safety expected from a file.
+//
+//	dialect:
+//	  comma: ";"
+//	  header: true
+//	columns:
+//	  - name: id
+//	    type: integer
+//	    required: true
+//	    unique: true
+//	  - name: status
+//	    allowed: [active, disabled]
+//	safety: escape-all
+//	signatures:
+//	  mycorp-macro: "(?i)mycorp\\.run\\("
+type Profile struct {
+	Dialect Dialect         `yaml:"dialect"`
+	Columns []ProfileColumn `yaml:"columns"`
+	// Safety names the safety preset whose triggers are reported:
+	// none, escape-all (the default) or full-safety.
+	Safety string `yaml:"safety"`
+	// Signatures maps names to the regular expressions of payloads
+	// reported on top of the default signatures of the library.
+	Signatures map[string]string `yaml:"signatures"`
+}
+
+// Dialect describes the CSV format of a file.
+type Dialect struct {
+	Comma  string `yaml:"comma"`  // Field delimiter (',' when empty)
+	Header bool   `yaml:"header"` // True when the first record is the header
+}
+
+// ProfileColumn describes a single column of a [Profile].
+type ProfileColumn struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"` // string, integer, number or boolean
+	Required bool     `yaml:"required"`
+	Allowed  []string `yaml:"allowed"`
+	Unique   bool     `yaml:"unique"`
+}
+
+// loadProfile reads the profile stored in the named YAML file.
+func loadProfile(name string) (Profile, error) {
+	var p Profile
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return p, err
+	}
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("profile %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// signatureSet returns the default signatures with those of the profile,
+// sorted by name.
+func (p Profile) signatureSet() (*csv.SignatureSet, error) {
+	names := make([]string, 0, len(p.Signatures))
+	for name := range p.Signatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := csv.DefaultSignatures()
+	for _, name := range names {
+		pattern, err := regexp.Compile(p.Signatures[name])
+		if err != nil {
+			return nil, fmt.Errorf("signature %q: %w", name, err)
+		}
+		set.Add(name, pattern)
+	}
+	return set, nil
+}
+
+// comma returns the field delimiter of the dialect.
+func (d Dialect) comma() (rune, error) {
+	return parseComma(d.Comma)
+}
+
+// parseComma parses a field delimiter made of a single character, ','
+// when empty. The escape sequence \t stands for a tab.
+func parseComma(s string) (rune, error) {
+	switch s {
+	case "":
+		return ',', nil
+	case `\t`:
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q", s)
+	}
+	return r, nil
+}
+
+// safetyOpts returns the safety preset of the profile.
+func (p Profile) safetyOpts() (csv.SafetyOpts, error) {
+	return parseSafety(p.Safety)
+}
+
+// parseSafety returns the named safety preset.
+func parseSafety(name string) (csv.SafetyOpts, error) {
+	switch name {
+	case "", "escape-all":
+		return csv.EscapeAll, nil
+	case "full-safety":
+		return csv.FullSafety, nil
+	case "none":
+		return csv.SafetyOpts{}, nil
+	}
+	return csv.SafetyOpts{}, fmt.Errorf("unknown safety preset %q", name)
+}
+
+// columnOpts returns the options validating the column.
+func (c ProfileColumn) columnOpts() (csv.ColumnOpts, error) {
+	opts := csv.ColumnOpts{
+		Name:     c.Name,
+		Required: c.Required,
+		Allowed:  c.Allowed,
+		Unique:   c.Unique,
+	}
+
+	for _, t := range []csv.ColumnType{csv.TypeString, csv.TypeInteger, csv.TypeNumber, csv.TypeBoolean} {
+		if c.Type == t.String() {
+			opts.Type = t
+			return opts, nil
+		}
+	}
+	if c.Type == "" {
+		return opts, nil
+	}
+	return opts, fmt.Errorf("column %q: unknown type %q", c.Name, c.Type)
+}
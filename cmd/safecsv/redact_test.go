@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	is := assert.New(t)
+
+	input := "id,email,ssn\n1,alice@example.com,123-45-6789\n2,,=1+1\n"
+
+	code, stdout, stderr := runCommand(input, "redact", "--columns", "email, ssn")
+	is.Equal(exitOK, code)
+	is.Empty(stderr)
+	is.Equal("id,email,ssn\n1,[REDACTED],[REDACTED]\n2,,[REDACTED]\n", stdout)
+
+	code, stdout, _ = runCommand(input, "redact", "--columns", "ssn", "--strategy", "mask")
+	is.Equal(exitOK, code)
+	is.Equal("id,email,ssn\n1,alice@example.com,*******6789\n2,,\" =1+1\"\n", stdout)
+
+	code, stdout, _ = runCommand("name\nalice\n", "redact", "--columns", "name", "--strategy", "hash")
+	is.Equal(exitOK, code)
+	is.Equal("name\n2bd806c97f0e00af1a1fc3328fa763a9269723c8db8fac4f93af71db186d6e90\n", stdout)
+
+	t.Setenv("SAFECSV_HASH_KEY", "secret")
+	_, keyed, _ := runCommand("name\nalice\n", "redact", "--columns", "name", "--strategy", "hash")
+	is.NotEqual(stdout, keyed)
+
+	code, _, stderr = runCommand(input, "redact", "--columns", "phone")
+	is.Equal(exitError, code)
+	is.Contains(stderr, `"phone"`)
+
+	code, _, stderr = runCommand(input, "redact", "--columns", "ssn", "--strategy", "rot13")
+	is.Equal(exitError, code)
+	is.Contains(stderr, `unknown strategy "rot13"`)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	csv "github.com/samber/go-safe-csv-writer"
+)
+
+// split implements `safecsv split --rows 100000 big.csv`, writing the
+// records of big.csv to big_001.csv, big_002.csv... each starting with the
+// header, and printing the names of the files created.
+func split(args []string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("split", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	rows := flags.Int("rows", 100000, "maximum number of records per file, header excluded")
+	header := flags.Bool("header", true, "repeat the first record as the header of every file")
+	output := flags.String("output", "", "printf `pattern` of the file names (defaults to <name>_%03d.csv)")
+	comma := flags.String("comma", ",", "field `delimiter` (\\t for tabs)")
+	safety := flags.String("safety", "escape-all", "safety `preset`: none, escape-all or full-safety")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() != 1 || *rows <= 0 {
+		fmt.Fprintln(stderr, "usage: safecsv split --rows 100000 [flags] file.csv")
+		return exitError
+	}
+
+	name := flags.Arg(0)
+	pattern := *output
+	if pattern == "" {
+		ext := filepath.Ext(name)
+		pattern = strings.ReplaceAll(strings.TrimSuffix(name, ext), "%", "%%") + "_%03d" + ext
+	}
+
+	opts, err := parseSafety(*safety)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+	delim, err := parseComma(*comma)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+
+	if err := splitFile(name, pattern, *rows, *header, opts, delim, stdout); err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// splitFile splits the named file into files named after pattern.
+func splitFile(name string, pattern string, rows int, header bool, opts csv.SafetyOpts, comma rune, stdout io.Writer) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewSafeReader(f)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	w := csv.NewChunkedWriter(rows, opts, func(index int) (io.WriteCloser, error) {
+		path := fmt.Sprintf(pattern, index+1)
+		fmt.Fprintln(stdout, path)
+		return os.Create(path)
+	})
+	w.Comma = comma
+
+	for first := true; ; first = false {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = w.Close()
+			return err
+		}
+
+		if first && header {
+			err = w.WriteHeader(record)
+		} else {
+			err = w.Write(record)
+		}
+		if err != nil {
+			_ = w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMerge(t *testing.T) {
+	is := assert.New(t)
+
+	big := writeFile(t, "big.csv", "id,name\n1,=a\n2,b\n3,c\n")
+	dir := filepath.Dir(big)
+
+	code, stdout, stderr := runCommand("", "split", "--rows", "2", big)
+	is.Equal(exitOK, code)
+	is.Empty(stderr)
+	parts := []string{filepath.Join(dir, "big_001.csv"), filepath.Join(dir, "big_002.csv")}
+	is.Equal(strings.Join(parts, "\n")+"\n", stdout)
+
+	b, err := os.ReadFile(parts[0])
+	is.NoError(err)
+	is.Equal("id,name\n1,\" =a\"\n2,b\n", string(b))
+	b, err = os.ReadFile(parts[1])
+	is.NoError(err)
+	is.Equal("id,name\n3,c\n", string(b))
+
+	reordered := writeFile(t, "reordered.csv", "name,id\nd,4\n")
+	code, stdout, stderr = runCommand("", "merge", parts[0], parts[1], reordered)
+	is.Equal(exitOK, code)
+	is.Empty(stderr)
+	is.Equal("id,name\n1,\" =a\"\n2,b\n3,c\n4,d\n", stdout)
+
+	code, _, stderr = runCommand("", "merge", parts[0], writeFile(t, "other.csv", "id,email\n5,e\n"))
+	is.Equal(exitError, code)
+	is.Contains(stderr, "header mismatch")
+
+	code, stdout, _ = runCommand("", "split", "--rows", "3", "--header=false", "--output", filepath.Join(dir, "part-%d.csv"), big)
+	is.Equal(exitOK, code)
+	is.Equal(filepath.Join(dir, "part-1.csv")+"\n"+filepath.Join(dir, "part-2.csv")+"\n", stdout)
+
+	code, _, _ = runCommand("", "split", "--rows", "0", big)
+	is.Equal(exitError, code)
+}
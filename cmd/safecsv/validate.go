@@ -0,0 +1,224 @@
+package main
+
+import (
+	stdcsv "encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	csv "github.com/samber/go-safe-csv-writer"
+)
+
+// A finding is a violation of a profile, written as a JSON line.
+type finding struct {
+	File    string `json:"file"`
+	Row     int64  `json:"row"` // Index of the record, header included
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column"`
+	Name    string `json:"name,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Rule    string `json:"rule"` // parse, header, fields, required, type, allowed or formula
+	Message string `json:"message"`
+}
+
+// validate implements `safecsv validate --profile profile.yaml file.csv...`.
+func validate(args []string, _ io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profile := flags.String("profile", "", "YAML `file` declaring the dialect, schema and safety of the files")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if *profile == "" || flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: safecsv validate --profile profile.yaml file.csv...")
+		return exitError
+	}
+
+	p, err := loadProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: %v\n", err)
+		return exitError
+	}
+
+	v, err := newValidator(p)
+	if err != nil {
+		fmt.Fprintf(stderr, "safecsv: profile %s: %v\n", *profile, err)
+		return exitError
+	}
+
+	code := exitOK
+	enc := json.NewEncoder(stdout)
+	for _, name := range flags.Args() {
+		findings, err := v.validateFile(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "safecsv: %v\n", err)
+			return exitError
+		}
+
+		for _, f := range findings {
+			if err := enc.Encode(f); err != nil {
+				fmt.Fprintf(stderr, "safecsv: %v\n", err)
+				return exitError
+			}
+			code = exitFindings
+		}
+	}
+	return code
+}
+
+// validator checks files against a profile.
+type validator struct {
+	comma      rune
+	header     bool
+	names      []string
+	safety     csv.SafetyOpts
+	signatures *csv.SignatureSet
+	columns    []*csv.SafeWriter // Validates the fields of each column
+}
+
+func newValidator(p Profile) (*validator, error) {
+	comma, err := p.Dialect.comma()
+	if err != nil {
+		return nil, err
+	}
+	safety, err := p.safetyOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := p.signatureSet()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{comma: comma, header: p.Dialect.Header, safety: safety, signatures: signatures}
+	for _, col := range p.Columns {
+		opts, err := col.columnOpts()
+		if err != nil {
+			return nil, err
+		}
+
+		// The validation of the library is reused: a field complies with
+		// the column when it can be written with its options.
+		w := csv.NewSafeWriter(io.Discard, csv.SafetyOpts{})
+		w.Columns = []csv.ColumnOpts{opts}
+		v.columns = append(v.columns, w)
+		v.names = append(v.names, col.Name)
+	}
+	return v, nil
+}
+
+// validateFile returns the findings of the named file.
+func (v *validator) validateFile(name string) ([]finding, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewSafeReader(f)
+	r.Comma = v.comma
+	r.FieldsPerRecord = -1
+	r.Detect = v.safety
+	r.Signatures = v.signatures
+
+	var findings []finding
+	for row := int64(0); ; row++ {
+		record, formulas, err := r.ReadWithFindings()
+		if err == io.EOF {
+			return findings, nil
+		}
+
+		var parseErr *stdcsv.ParseError
+		if errors.As(err, &parseErr) {
+			return append(findings, finding{
+				File:    name,
+				Row:     row,
+				Line:    parseErr.Line,
+				Column:  parseErr.Column,
+				Rule:    "parse",
+				Message: parseErr.Err.Error(),
+			}), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		add := func(f finding) {
+			f.File, f.Row, f.Line = name, row, line
+			if f.Column < len(v.names) {
+				f.Name = v.names[f.Column]
+			}
+			findings = append(findings, f)
+		}
+
+		for _, formula := range formulas {
+			if formula.Trigger != 0 {
+				add(finding{
+					Column:  formula.Column,
+					Value:   formula.Value,
+					Rule:    "formula",
+					Message: fmt.Sprintf("cell starts with %q", formula.Trigger),
+				})
+			}
+			if formula.Signature != "" {
+				add(finding{
+					Column:  formula.Column,
+					Value:   formula.Value,
+					Rule:    "signature",
+					Message: fmt.Sprintf("cell matches signature %q", formula.Signature),
+				})
+			}
+		}
+
+		if len(v.columns) == 0 {
+			continue
+		}
+
+		if len(record) != len(v.columns) {
+			add(finding{
+				Column:  len(record),
+				Rule:    "fields",
+				Message: fmt.Sprintf("expected %d fields, got %d", len(v.columns), len(record)),
+			})
+		}
+
+		if row == 0 && v.header {
+			for i, field := range record {
+				if i < len(v.names) && strings.TrimSpace(field) != v.names[i] {
+					add(finding{Column: i, Value: field, Rule: "header", Message: fmt.Sprintf("expected column %q", v.names[i])})
+				}
+			}
+			continue
+		}
+
+		for i, field := range record {
+			if i >= len(v.columns) {
+				break
+			}
+			if err := v.columns[i].Write([]string{field}); err != nil {
+				add(finding{Column: i, Value: field, Rule: rule(err), Message: errors.Unwrap(err).Error()})
+			}
+		}
+	}
+}
+
+// rule returns the name of the rule broken by a field rejected with err.
+func rule(err error) string {
+	switch {
+	case errors.Is(err, csv.ErrRequiredField):
+		return "required"
+	case errors.Is(err, csv.ErrTypeMismatch):
+		return "type"
+	case errors.Is(err, csv.ErrValueNotAllowed):
+		return "allowed"
+	case errors.Is(err, csv.ErrDuplicateValue):
+		return "unique"
+	}
+	return "invalid"
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProfile = `
+dialect:
+  comma: ";"
+  header: true
+columns:
+  - name: id
+    type: integer
+    required: true
+  - name: status
+    allowed: [active, disabled]
+  - name: comment
+safety: escape-all
+`
+
+func TestValidate(t *testing.T) {
+	is := assert.New(t)
+
+	profile := writeFile(t, "profile.yaml", testProfile)
+
+	valid := writeFile(t, "valid.csv", "id;status;comment\n1;active;\" =ok\"\n")
+	code, stdout, stderr := runCommand("", "validate", "--profile", profile, valid)
+	is.Equal(exitOK, code)
+	is.Empty(stdout)
+	is.Empty(stderr)
+
+	invalid := writeFile(t, "invalid.csv", "id;state;comment\nx;active;=1+1\n;other\n")
+	code, stdout, stderr = runCommand("", "validate", "--profile", profile, invalid)
+	is.Equal(exitFindings, code)
+	is.Empty(stderr)
+	is.Equal(`{"file":"`+invalid+`","row":0,"line":1,"column":1,"name":"status","value":"state","rule":"header","message":"expected column \"status\""}
+{"file":"`+invalid+`","row":1,"line":2,"column":2,"name":"comment","value":"=1+1","rule":"formula","message":"cell starts with '='"}
+{"file":"`+invalid+`","row":1,"line":2,"column":0,"name":"id","value":"x","rule":"type","message":"value does not match column type"}
+{"file":"`+invalid+`","row":2,"line":3,"column":2,"name":"comment","rule":"fields","message":"expected 3 fields, got 2"}
+{"file":"`+invalid+`","row":2,"line":3,"column":0,"name":"id","rule":"required","message":"required field is empty"}
+{"file":"`+invalid+`","row":2,"line":3,"column":1,"name":"status","value":"other","rule":"allowed","message":"value not allowed"}
+`, stdout)
+
+	broken := writeFile(t, "broken.csv", "id;status;comment\n1;\"active\n")
+	code, stdout, _ = runCommand("", "validate", "--profile", profile, broken)
+	is.Equal(exitFindings, code)
+	is.Contains(stdout, `"rule":"parse"`)
+
+	code, _, stderr = runCommand("", "validate", valid)
+	is.Equal(exitError, code)
+	is.Contains(stderr, "usage")
+
+	code, _, stderr = runCommand("", "validate", "--profile", writeFile(t, "bad.yaml", "safety: paranoid\n"), valid)
+	is.Equal(exitError, code)
+	is.Contains(stderr, `unknown safety preset "paranoid"`)
+}
+
+func TestValidateSignatures(t *testing.T) {
+	is := assert.New(t)
+
+	profile := writeFile(t, "profile.yaml", "signatures:\n  mycorp-macro: \"(?i)mycorp\\\\.run\\\\(\"\n")
+
+	input := writeFile(t, "input.csv", "name,note\nalice,see [budget.xlsx]Q1!A1\nbob,MyCorp.Run(x)\n")
+	code, stdout, stderr := runCommand("", "validate", "--profile", profile, input)
+	is.Equal(exitFindings, code)
+	is.Empty(stderr)
+	is.Equal(`{"file":"`+input+`","row":1,"line":2,"column":1,"value":"see [budget.xlsx]Q1!A1","rule":"signature","message":"cell matches signature \"external-workbook\""}
+{"file":"`+input+`","row":2,"line":3,"column":1,"value":"MyCorp.Run(x)","rule":"signature","message":"cell matches signature \"mycorp-macro\""}
+`, stdout)
+
+	code, _, stderr = runCommand("", "validate", "--profile", writeFile(t, "bad.yaml", "signatures:\n  broken: \"(\"\n"), input)
+	is.Equal(exitError, code)
+	is.Contains(stderr, `signature "broken"`)
+}
+
+func TestValidateUnique(t *testing.T) {
+	is := assert.New(t)
+
+	profile := writeFile(t, "profile.yaml", "columns:\n  - name: id\n    unique: true\n")
+
+	input := writeFile(t, "input.csv", "1\n2\n1\n")
+	code, stdout, stderr := runCommand("", "validate", "--profile", profile, input)
+	is.Equal(exitFindings, code)
+	is.Empty(stderr)
+	is.Equal(`{"file":"`+input+`","row":2,"line":3,"column":0,"name":"id","value":"1","rule":"unique","message":"duplicate value"}
+`, stdout)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSource = `package models
+
+type User struct {
+	ID       int64   ` + "`csv:\"id\"`" + `
+	Email    string  ` + "`csv:\"email,omitempty\"`" + `
+	Score    float64
+	Admin    bool    ` + "`csv:\"is_admin\"`" + `
+	Password string  ` + "`csv:\"-\"`" + `
+	internal string
+}
+
+type Tags []string
+`
+
+func TestGenerate(t *testing.T) {
+	is := assert.New(t)
+
+	out, err := generate("user.go", []byte(testSource), "User")
+	is.NoError(err)
+	is.Equal(`// Code generated by safecsvgen; DO NOT EDIT.
+
+package models
+
+import csv "github.com/samber/go-safe-csv-writer"
+
+// UserHeader is the header of the records written by WriteUser.
+var UserHeader = []string{"id", "email", "Score", "is_admin"}
+
+// WriteUser writes v as a single CSV record, in the order of UserHeader.
+func WriteUser(w *csv.SafeWriter, v User) error {
+	return w.WriteCells([]csv.Cell{
+		csv.IntCell(v.ID),
+		csv.StringCell(v.Email),
+		csv.NumberCell(v.Score),
+		csv.BoolCell(v.Admin),
+	})
+}
+`, string(out))
+
+	_, err = generate("user.go", []byte(testSource), "Tags")
+	is.EqualError(err, "type Tags is not a struct")
+
+	_, err = generate("user.go", []byte(testSource), "Group")
+	is.EqualError(err, "type Group not found")
+
+	_, err = generate("user.go", []byte("package models\n\ntype Group struct {\n\tUsers []User\n}\n"), "Group")
+	is.EqualError(err, "type Group: field Users: unsupported type []User")
+
+	out, err = generate("point.go", []byte("package models\n\ntype Point struct {\n\tX float32\n}\n"), "Point")
+	is.NoError(err)
+	is.Contains(string(out), "csv.Float32Cell(v.X),")
+}
+
+func TestGenerateOrder(t *testing.T) {
+	is := assert.New(t)
+
+	src := "package models\n\ntype Account struct {\n" +
+		"\tName string `csv:\"name,order=2\"`\n" +
+		"\tEmail string\n" +
+		"\tID string `csv:\"id,order=1\"`\n" +
+		"}\n"
+	out, err := generate("account.go", []byte(src), "Account")
+	is.NoError(err)
+	is.Contains(string(out), `var AccountHeader = []string{"id", "name", "Email"}`)
+	is.Contains(string(out), "csv.StringCell(v.ID),\n\t\tcsv.StringCell(v.Name),\n\t\tcsv.StringCell(v.Email),")
+
+	_, err = generate("account.go", []byte("package models\n\ntype Account struct {\n\tID string `csv:\"id,order=a\"`\n}\n"), "Account")
+	is.EqualError(err, `type Account: field ID: invalid "order=a" option`)
+}
@@ -0,0 +1,18 @@
+//go:build go1.18
+// +build go1.18
+
+package csv
+
+// MapWrite writes the record returned by iteratee for every item of
+// collection to w, then flushes w, returning any error from the Flush. The
+// iteratee receives the item and its index, like the callbacks of
+// github.com/samber/lo.
+func MapWrite[T any](w *SafeWriter, collection []T, iteratee func(item T, index int) []string) error {
+	for i, item := range collection {
+		if err := w.Write(iteratee(item, i)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package csv
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapWrite(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+
+	err := MapWrite(w, []string{"alice", "=bob"}, func(name string, i int) []string {
+		return []string{strconv.Itoa(i), name}
+	})
+	is.NoError(err)
+	is.Equal("0,alice\n1,\" =bob\"\n", buff.String())
+
+	w.Columns = []ColumnOpts{{Required: true}}
+	err = MapWrite(w, []int{1}, func(int, int) []string { return []string{""} })
+	is.ErrorIs(err, ErrRequiredField)
+}
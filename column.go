@@ -0,0 +1,277 @@
+package csv
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Transformer rewrites the value of a field before it is sanitized.
+// Returning an error aborts the record.
+type Transformer func(field string) (string, error)
+
+// ColumnOpts configures how a single column of a [SafeWriter] is written.
+type ColumnOpts struct {
+	// Name identifies the column in errors. It defaults to the name given
+	// to [SafeWriter.WriteHeader].
+	Name string
+	// Transformers are applied in order to every field of the column,
+	// including trusted cells. Their output is then sanitized as usual: a
+	// trusted cell is only left unsanitized when no transformer changes its
+	// value.
+	Transformers []Transformer
+	// Width pads fields shorter than Width runes with spaces, after
+	// sanitization. Wider fields are written unchanged. Padding on the
+	// left makes the field start with a space, so it is quoted.
+	Width int
+	// Align selects on which side of the value padding is added.
+	Align Alignment
+	// Phone marks a column holding phone numbers. Values that look like a
+	// phone number, such as +33 6 12 34 56 78, are quoted instead of being
+	// escaped, so the leading + is not prefixed. Values that spreadsheet
+	// software would read as a number, such as 0612345678 or +33612345678,
+	// also start with a tab unless the column has a TextHint, so that they
+	// keep their leading zeros and +. Other values are sanitized as usual.
+	Phone bool
+	// BoolFormat overrides [SafeWriter.BoolFormat] for the column.
+	BoolFormat BoolFormat
+	// Allowed, when not empty, lists the only values accepted in the
+	// column, after transformation. Other values are rejected with
+	// [ErrValueNotAllowed].
+	Allowed []string
+	// Required rejects empty fields with [ErrRequiredField].
+	Required bool
+	// Default is written by [SafeWriter.WriteMap] and [SafeWriter.WriteStruct]
+	// when the record has no value for the column. It is sanitized and
+	// validated like any field.
+	Default string
+	// Type rejects non-empty fields that are not valid values of the type
+	// with [ErrTypeMismatch].
+	Type ColumnType
+	// TextHint marks the non-empty fields of the column as text, so that
+	// identifiers such as 007 or 1E5 are not converted to numbers when the
+	// file is opened in a spreadsheet. Hinted fields are quoted.
+	TextHint TextHint
+	// Unique rejects fields whose value was already written to the column
+	// with [ErrDuplicateValue], such as duplicated primary keys caused by a
+	// bad join. Values are tracked by their 64-bit hash, whatever their
+	// length, so distinct values may very rarely be reported as duplicates.
+	Unique bool
+	// UniqueLimit, when positive, caps the number of values tracked by a
+	// unique column, bounding its memory use: new values are then rejected
+	// with [ErrUniqueLimit].
+	UniqueLimit int
+	// Null selects what [SafeWriter.WriteStruct] writes for null values:
+	// nil pointers, and values implementing [database/sql/driver.Valuer]
+	// that return nil, such as invalid sql.NullString values or absent
+	// mo.Option values.
+	Null NullPolicy
+	// NullValue is written for null values with [NullAsValue], such as
+	// NULL or \N. It is sanitized and validated like any field.
+	NullValue string
+	// List selects how [SafeWriter.WriteStruct] writes the slices, arrays
+	// and maps of the column.
+	List ListFormat
+	// ListSeparator joins the elements of lists with [ListJoined] ("|" when
+	// empty). It cannot contain the field delimiter, quotes or line
+	// terminators, and elements containing it are rejected with
+	// [ErrListElement].
+	ListSeparator string
+}
+
+// ListFormat selects how the slices, arrays and maps of a column are
+// written.
+type ListFormat int
+
+const (
+	// ListDefault writes lists like the fmt package does, such as [a b].
+	ListDefault ListFormat = iota
+	// ListJSON writes lists as JSON arrays and objects.
+	ListJSON
+	// ListJoined joins the elements of lists with [ColumnOpts.ListSeparator],
+	// and the entries of maps as key=value, sorted by key.
+	ListJoined
+)
+
+func (col ColumnOpts) listSeparator() string {
+	if col.ListSeparator == "" {
+		return "|"
+	}
+	return col.ListSeparator
+}
+
+// NullPolicy selects how the null values of a column are written.
+type NullPolicy int
+
+const (
+	// NullAsEmpty writes null values as empty fields.
+	NullAsEmpty NullPolicy = iota
+	// NullAsValue writes null values as [ColumnOpts.NullValue].
+	NullAsValue
+	// NullAsDefault writes null values as [ColumnOpts.Default].
+	NullAsDefault
+)
+
+// null returns the field written for the null values of the column.
+func (col ColumnOpts) null() string {
+	switch col.Null {
+	case NullAsValue:
+		return col.NullValue
+	case NullAsDefault:
+		return col.Default
+	default:
+		return ""
+	}
+}
+
+// TextHint selects how the fields of a column are marked as text.
+type TextHint int
+
+const (
+	// TextHintNone writes fields unchanged.
+	TextHintNone TextHint = iota
+	// TextHintApostrophe prefixes fields with an apostrophe, the prefix
+	// Excel uses for numbers typed as text.
+	TextHintApostrophe
+	// TextHintTab prefixes fields with a tab, which is not displayed.
+	TextHintTab
+)
+
+// prefix returns the characters written before the fields hinted with h.
+func (h TextHint) prefix() string {
+	switch h {
+	case TextHintApostrophe:
+		return "'"
+	case TextHintTab:
+		return "\t"
+	default:
+		return ""
+	}
+}
+
+// PhoneNumberColumn is a column preset for phone numbers.
+var PhoneNumberColumn = ColumnOpts{
+	Phone: true,
+}
+
+// computedColumn is a column added by [SafeWriter.AddColumn].
+type computedColumn struct {
+	name    string
+	compute func(record []string) string
+}
+
+// AddColumn appends a computed column to every record. compute receives the
+// record as given to the writer, before projection, and its result is
+// sanitized like any untrusted field, even for trusted records. When a
+// header is written, name is appended to it.
+//
+// Computed columns come after the other columns, in the order they are
+// added, and [SafeWriter.Columns] apply to them by position.
+func (w *SafeWriter) AddColumn(name string, compute func(record []string) string) {
+	w.computed = append(w.computed, computedColumn{name: name, compute: compute})
+}
+
+// prepareComputed prepares the computed columns of record.
+func (w *SafeWriter) prepareComputed(record []string) error {
+	for _, col := range w.computed {
+		prepared, err := w.prepareField(len(w.fields), col.compute(record), CellPolicy{})
+		if err != nil {
+			return err
+		}
+		w.fields = append(w.fields, prepared)
+	}
+	return nil
+}
+
+// column returns the options of the column at position n.
+func (w *SafeWriter) column(n int) ColumnOpts {
+	if n < len(w.Columns) {
+		return w.Columns[n]
+	}
+	return ColumnOpts{}
+}
+
+// transform applies the transformers of the column to field, in order.
+func (col ColumnOpts) transform(field string) (string, error) {
+	for _, transform := range col.Transformers {
+		transformed, err := transform(field)
+		if err != nil {
+			return "", err
+		}
+		field = transformed
+	}
+	return field, nil
+}
+
+// validate checks field against the constraints of the column.
+func (col ColumnOpts) validate(field string) error {
+	if col.Required && field == "" {
+		return ErrRequiredField
+	}
+	if field != "" && !col.Type.matches(field) {
+		return ErrTypeMismatch
+	}
+	if len(col.Allowed) > 0 && !contains(col.Allowed, field) {
+		return ErrValueNotAllowed
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// boolFormat returns the rendering of booleans at position n.
+func (w *SafeWriter) boolFormat(n int) BoolFormat {
+	if format := w.column(n).BoolFormat; format != (BoolFormat{}) {
+		return format
+	}
+	if w.BoolFormat != (BoolFormat{}) {
+		return w.BoolFormat
+	}
+	return BoolTrueFalse
+}
+
+// pad pads field to the width of the column.
+func (col ColumnOpts) pad(field string) string {
+	missing := col.Width - utf8.RuneCountInString(field)
+	if missing <= 0 {
+		return field
+	}
+
+	if col.Align == AlignRight {
+		return strings.Repeat(" ", missing) + field
+	}
+	return field + strings.Repeat(" ", missing)
+}
+
+// isPhoneNumber reports whether field is made of digits and the punctuation
+// found in phone numbers only, optionally starting with a +.
+func isPhoneNumber(field string) bool {
+	digits := 0
+	for i := 0; i < len(field); i++ {
+		c := field[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '+' && i == 0:
+		case i > 0 && (c == ' ' || c == '-' || c == '.'):
+		case c == '(' || c == ')':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// isNumericPhone reports whether the phone number field would be read as a
+// number by spreadsheet software, such as 0612345678 or 555.0100.
+func isNumericPhone(field string) bool {
+	_, err := strconv.ParseFloat(field, 64)
+	return err == nil
+}
@@ -0,0 +1,155 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnPadding(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{
+		{Width: 6},
+		{Width: 4, Align: AlignRight},
+	}
+	is.NoError(w.Write([]string{"id", "7", "extra"}))
+	is.NoError(w.Write([]string{"=A1", "12345", "x"}))
+	is.NoError(w.WriteCells([]Cell{StringCell("é"), NumberCell(-1)}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id    ,\"   7\",extra\n\" =A1  \",12345,x\né     ,\"  -1\"\n", buff.String())
+}
+
+func TestPhoneNumberColumn(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{PhoneNumberColumn, PhoneNumberColumn, PhoneNumberColumn, PhoneNumberColumn}
+	is.NoError(w.Write([]string{"+33 6 12 34 56 78", "0612345678", "+1 (555) 010-9999", "+1+cmd|calc"}))
+	is.NoError(w.Write([]string{"-1-800", "", "=A1", "++33"}))
+	is.NoError(w.Write([]string{"+33612345678", "003361234567890", "555.0100", "+"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"+33 6 12 34 56 78\",\"\t0612345678\",\"+1 (555) 010-9999\",\" +1+cmd|calc\"\n\" -1-800\",,\" =A1\",\" ++33\"\n\"\t+33612345678\",\"\t003361234567890\",\"\t555.0100\",\" +\"\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{Phone: true, TextHint: TextHintApostrophe}}
+	is.NoError(w.Write([]string{"0612345678"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"'0612345678\"\n", buff.String())
+
+	is.True(isNumericPhone("+33612345678"))
+	is.False(isNumericPhone("+"))
+	is.True(isNumericPhone("555.0100"))
+	is.False(isNumericPhone("06 12"))
+	is.False(isNumericPhone("555-0100"))
+
+	is.True(isPhoneNumber("+33612345678"))
+	is.True(isPhoneNumber("(555) 010.9999"))
+	is.False(isPhoneNumber(""))
+	is.False(isPhoneNumber("+"))
+	is.False(isPhoneNumber(" 123"))
+	is.False(isPhoneNumber("12+3"))
+}
+
+func TestColumnTextHint(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	opts := EscapeAll
+	opts.LongNumberDigits = 12
+	w := NewSafeWriter(&buff, opts)
+	w.Columns = []ColumnOpts{{TextHint: TextHintApostrophe}, {TextHint: TextHintTab}, {}}
+	is.NoError(w.Write([]string{"007", "1E5", "007"}))
+	is.NoError(w.Write([]string{"=A1", "4111111111111111", "4111111111111111"}))
+	is.NoError(w.Write([]string{"", "", ""}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"'007\",\"\t1E5\",007\n\"' =A1\",\"\t4111111111111111\",\"\t4111111111111111\"\n,,\n", buff.String())
+}
+
+func TestColumnAllowed(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{
+		{},
+		{Name: "status", Allowed: []string{"active", "disabled", ""}},
+		{Allowed: []string{"1", "0"}, BoolFormat: BoolOneZero},
+	}
+	is.NoError(w.Write([]string{"a", "active", "1"}))
+	is.NoError(w.WriteCells([]Cell{StringCell("b"), StringCell(""), BoolCell(false)}))
+
+	err := w.Write([]string{"c", "deleted", "1"})
+	is.ErrorIs(err, ErrValueNotAllowed)
+	is.EqualError(err, `csv: record 2, column 1 ("status"): value not allowed`)
+	is.Equal(&ColumnError{Row: 2, Column: 1, Name: "status", Value: "deleted", Err: ErrValueNotAllowed}, err)
+
+	err = w.Write([]string{"c", "active", "true"})
+	is.EqualError(err, `csv: record 2, column 2: value not allowed`)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("a,active,1\nb,,0\n", buff.String())
+}
+
+func TestAddColumn(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.SelectColumns("name")
+	w.AddColumn("full", func(record []string) string { return strings.TrimSpace(record[1] + " " + record[0]) })
+	w.AddColumn("formula", func(record []string) string { return "=" + record[0] })
+	w.Columns = []ColumnOpts{{}, {Required: true}}
+
+	header := []string{"name", "first"}
+	is.NoError(w.WriteHeader(header))
+	is.Equal([]string{"name", "first"}, header)
+	is.NoError(w.Write([]string{"Doe", "John"}))
+	is.NoError(w.WriteRecordUnsafe([]string{"=Smith", "Jane"}))
+	is.NoError(w.WriteMap(map[string]string{"name": "Roe", "first": "Rick"}))
+	is.ErrorIs(w.Write([]string{"", ""}), ErrRequiredField)
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("name,full,formula\nDoe,John Doe,\" =Doe\"\n=Smith,Jane =Smith,\" ==Smith\"\nRoe,Rick Roe,\" =Roe\"\n", buff.String())
+}
+
+func TestColumnUnique(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{Name: "id", Unique: true}, {Required: true}, {Unique: true, UniqueLimit: 2}}
+	is.NoError(w.WriteHeader([]string{"id", "name", "code"}))
+	is.NoError(w.Write([]string{"1", "a", "x"}))
+
+	// Rejected records do not track their values.
+	is.ErrorIs(w.Write([]string{"2", "", "y"}), ErrRequiredField)
+	is.NoError(w.Write([]string{"2", "b", "y"}))
+
+	err := w.Write([]string{"1", "c", "z"})
+	is.EqualError(err, `csv: record 3, column 0 ("id"): duplicate value`)
+	is.Equal(&ColumnError{Row: 3, Column: 0, Name: "id", Value: "1", Err: ErrDuplicateValue}, err)
+
+	is.ErrorIs(w.Write([]string{"3", "c", "z"}), ErrUniqueLimit)
+	is.ErrorIs(w.WriteAllParallel([][]string{{"3", "c", "x"}}, ParallelOpts{}), ErrDuplicateValue)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,name,code\n1,a,x\n2,b,y\n", buff.String())
+}
@@ -0,0 +1,142 @@
+package csv
+
+import (
+	"bytes"
+	stdcsv "encoding/csv"
+	"io"
+	"unicode/utf8"
+)
+
+// CopyOpts configures [SafeCopy].
+type CopyOpts struct {
+	// Comma is the field delimiter of both the input and the output
+	// (',' when 0).
+	Comma rune
+	// OutputComma, when not 0, is the field delimiter of the output,
+	// overriding Comma.
+	OutputComma rune
+	// UseCRLF makes the output use \r\n as the line terminator.
+	UseCRLF bool
+	// Windows1252Fallback decodes the fields that are not valid UTF-8 as
+	// Windows-1252, the usual encoding of legacy spreadsheet exports,
+	// instead of copying their bytes unchanged.
+	Windows1252Fallback bool
+	// BareCR accepts inputs using \r alone as their line terminator. See
+	// [SafeReader.BareCR].
+	BareCR bool
+}
+
+// SafeCopy reads CSV records from src and writes them to dst, sanitized
+// according to opts. It returns the number of records copied.
+//
+// Records may have a variable number of fields, and quotes are parsed
+// leniently, so that dirty inputs can still be hardened. Byte order marks
+// are handled like [SafeReader] does.
+func SafeCopy(dst io.Writer, src io.Reader, opts SafetyOpts, copyOpts CopyOpts) (int64, error) {
+	comma := copyOpts.Comma
+	if comma == 0 {
+		comma = ','
+	}
+
+	r := newLenientReader(src, comma)
+	r.BareCR = copyOpts.BareCR
+
+	w := NewSafeWriter(dst, opts)
+	w.Comma = comma
+	if copyOpts.OutputComma != 0 {
+		w.Comma = copyOpts.OutputComma
+	}
+	w.UseCRLF = copyOpts.UseCRLF
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return w.stats.Records, err
+		}
+
+		if copyOpts.Windows1252Fallback {
+			for i, field := range record {
+				if !utf8.ValidString(field) {
+					record[i] = decodeWindows1252(field)
+				}
+			}
+		}
+
+		if err := w.Write(record); err != nil {
+			return w.stats.Records, err
+		}
+	}
+
+	w.Flush()
+	return w.stats.Records, w.Error()
+}
+
+// SanitizeRecordsFrom reads all the remaining records from r and returns
+// them sanitized according to opts, as [SafeWriter.Write] would write them,
+// without the quoting. It is meant for records that are passed on in memory
+// rather than written as CSV.
+func SanitizeRecordsFrom(r *stdcsv.Reader, opts SafetyOpts) ([][]string, error) {
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		for i, field := range record {
+			record[i] = opts.Sanitize(field)
+		}
+	}
+	return records, nil
+}
+
+// newLenientReader returns a SafeReader accepting dirty inputs.
+func newLenientReader(src io.Reader, comma rune) *SafeReader {
+	r := NewSafeReader(src)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r
+}
+
+// NewSanitizingReader returns a reader of the CSV records of r, sanitized
+// according to opts, so that they can be streamed to an HTTP request or an
+// upload without being buffered. Records are read as by [SafeCopy].
+//
+// Errors reading or parsing r are returned by the Read method of the
+// returned reader, once the records before them have been read.
+func NewSanitizingReader(r io.Reader, opts SafetyOpts) io.Reader {
+	sr := &sanitizingReader{r: newLenientReader(r, ',')}
+	sr.w = NewSafeWriter(&sr.buf, opts)
+	return sr
+}
+
+// sanitizingReader encodes records one at a time, as they are read.
+type sanitizingReader struct {
+	r   *SafeReader
+	w   *SafeWriter
+	buf bytes.Buffer
+	err error
+}
+
+func (s *sanitizingReader) Read(p []byte) (int, error) {
+	for s.buf.Len() == 0 && s.err == nil {
+		var record []string
+		record, s.err = s.r.Read()
+		if s.err != nil {
+			break
+		}
+
+		if err := s.w.Write(record); err != nil {
+			s.err = err
+		}
+		s.w.Flush()
+	}
+
+	if s.buf.Len() > 0 {
+		return s.buf.Read(p)
+	}
+	return 0, s.err
+}
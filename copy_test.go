@@ -0,0 +1,93 @@
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeWindows1252(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("abc", decodeWindows1252("abc"))
+	is.Equal("café €5 “quoted” ÿ\u0081", decodeWindows1252("caf\xe9 \x805 \x93quoted\x94 \xff\x81"))
+}
+
+func TestSafeCopy(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	src := "id;name;comment\n1;=A1;caf\xe9\n2;\"a;b\";ok;extra\n"
+
+	n, err := SafeCopy(&buff, strings.NewReader(src), EscapeAll, CopyOpts{Comma: ';'})
+	is.NoError(err)
+	is.EqualValues(3, n)
+	is.Equal("id;name;comment\n1;\" =A1\";caf\xe9\n2;\"a;b\";ok;extra\n", buff.String())
+
+	buff.Reset()
+	n, err = SafeCopy(&buff, strings.NewReader(src), EscapeAll, CopyOpts{Comma: ';', Windows1252Fallback: true})
+	is.NoError(err)
+	is.EqualValues(3, n)
+	is.Equal("id;name;comment\n1;\" =A1\";café\n2;\"a;b\";ok;extra\n", buff.String())
+
+	buff.Reset()
+	n, err = SafeCopy(&buff, strings.NewReader(src), EscapeAll, CopyOpts{Comma: ';', OutputComma: ',', UseCRLF: true})
+	is.NoError(err)
+	is.EqualValues(3, n)
+	is.Equal("id,name,comment\r\n1,\" =A1\",caf\xe9\r\n2,a;b,ok,extra\r\n", buff.String())
+
+	buff.Reset()
+	n, err = SafeCopy(&buff, strings.NewReader("a,b\n\"c"), FullSafety, CopyOpts{})
+	is.NoError(err)
+	is.EqualValues(2, n)
+	is.Equal("\"a\",\"b\"\n\"c\"\n", buff.String())
+
+	buff.Reset()
+	_, err = SafeCopy(&buff, strings.NewReader("\xef\xbb\xbf=a,b\n"), EscapeAll, CopyOpts{})
+	is.NoError(err)
+	is.Equal("\" =a\",b\n", buff.String())
+
+	_, err = SafeCopy(&buff, strings.NewReader("a"), FullSafety, CopyOpts{Comma: '"'})
+	is.Error(err)
+}
+
+func TestNewSanitizingReader(t *testing.T) {
+	is := assert.New(t)
+
+	out, err := io.ReadAll(NewSanitizingReader(strings.NewReader("\ufeffid,name\n1,=A1\n2,@SUM(A1:A2),extra\n"), EscapeAll))
+	is.NoError(err)
+	is.Equal("id,name\n1,\" =A1\"\n2,\" @SUM(A1:A2)\",extra\n", string(out))
+
+	r := NewSanitizingReader(strings.NewReader("a,b\n\"c"), FullSafety)
+	p := make([]byte, 3)
+	n, err := r.Read(p)
+	is.NoError(err)
+	is.Equal("\"a\"", string(p[:n]))
+
+	out, err = io.ReadAll(r)
+	is.NoError(err)
+	is.Equal(",\"b\"\n\"c\"\n", string(out))
+
+	out, err = io.ReadAll(NewSanitizingReader(strings.NewReader("a,b\n1,2\"\"\n"), EscapeAll))
+	is.NoError(err)
+	is.Equal("a,b\n1,\"2\"\"\"\"\"\n", string(out))
+}
+
+func TestSanitizeRecordsFrom(t *testing.T) {
+	is := assert.New(t)
+
+	opts := EscapeAll
+	opts.LongNumberDigits = 12
+
+	records, err := SanitizeRecordsFrom(stdcsv.NewReader(strings.NewReader("id,card\n=1,4111111111111111\n")), opts)
+	is.NoError(err)
+	is.Equal([][]string{{"id", "card"}, {" =1", "\t4111111111111111"}}, records)
+
+	records, err = SanitizeRecordsFrom(stdcsv.NewReader(strings.NewReader("a,b\n\"c")), opts)
+	is.Error(err)
+	is.Nil(records)
+}
@@ -0,0 +1,62 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A FileCreator creates named files, so that the writers producing several
+// outputs work the same over local disks, in-memory file systems for tests,
+// and cloud storage adapters.
+type FileCreator interface {
+	// CreateFile creates the named file, truncating it if it exists. Names
+	// are slash-separated paths, as in [fs.FS].
+	CreateFile(name string) (io.WriteCloser, error)
+}
+
+// The FileCreatorFunc type is an adapter to allow the use of ordinary
+// functions as a [FileCreator].
+type FileCreatorFunc func(name string) (io.WriteCloser, error)
+
+// CreateFile calls f(name).
+func (f FileCreatorFunc) CreateFile(name string) (io.WriteCloser, error) {
+	return f(name)
+}
+
+// DirCreator returns a FileCreator creating files in the directory dir of
+// the local disk. Names must be valid according to [fs.ValidPath]: files
+// cannot be created outside of dir.
+func DirCreator(dir string) FileCreator {
+	return FileCreatorFunc(func(name string) (io.WriteCloser, error) {
+		if !fs.ValidPath(name) || strings.Contains(name, `\`) {
+			return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+		}
+		return os.Create(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// ChunkFiles returns a function creating the outputs of a [ChunkedWriter]
+// with fsys, named after pattern formatted with the index of the chunk,
+// starting at 1, such as "export_%03d.csv".
+func ChunkFiles(fsys FileCreator, pattern string) func(index int) (io.WriteCloser, error) {
+	return func(index int) (io.WriteCloser, error) {
+		return fsys.CreateFile(fmt.Sprintf(pattern, index+1))
+	}
+}
+
+// PartitionFiles returns a function creating the outputs of a
+// [PartitionedWriter] with fsys, named after pattern formatted with the key
+// of the partition, such as "export_%s.csv". Keys that are empty or that
+// are not a single path element, such as "../x", are rejected.
+func PartitionFiles(fsys FileCreator, pattern string) func(key string) (io.WriteCloser, error) {
+	return func(key string) (io.WriteCloser, error) {
+		if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+			return nil, fmt.Errorf("csv: invalid partition key %q", key)
+		}
+		return fsys.CreateFile(fmt.Sprintf(pattern, key))
+	}
+}
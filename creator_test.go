@@ -0,0 +1,40 @@
+package csv
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirCreator(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+	w := NewPartitionedWriter(0, EscapeAll, PartitionFiles(DirCreator(dir), "export_%s.csv"))
+	is.NoError(w.WriteHeader([]string{"country"}))
+	is.NoError(w.Write([]string{"fr"}))
+	is.Error(w.Write([]string{"../fr"}))
+	is.Error(w.Write([]string{".."}))
+	is.Error(w.Write([]string{""}))
+	is.NoError(w.Close())
+
+	b, err := os.ReadFile(filepath.Join(dir, "export_fr.csv"))
+	is.NoError(err)
+	is.Equal("country\nfr\n", string(b))
+
+	c := NewChunkedWriter(1, EscapeAll, ChunkFiles(DirCreator(dir), "export_%03d.csv"))
+	is.NoError(c.Write([]string{"a"}))
+	is.NoError(c.Write([]string{"b"}))
+	is.NoError(c.Close())
+
+	b, err = os.ReadFile(filepath.Join(dir, "export_002.csv"))
+	is.NoError(err)
+	is.Equal("b\n", string(b))
+
+	_, err = DirCreator(dir).CreateFile("../export.csv")
+	is.True(errors.Is(err, fs.ErrInvalid))
+}
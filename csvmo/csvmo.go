@@ -0,0 +1,31 @@
+// Package csvmo offers a result-style API over
+// github.com/samber/go-safe-csv-writer for codebases using
+// github.com/samber/mo.
+//
+// Errors are those of the writer, such as a *csv.ColumnError, and can be
+// inspected with errors.As on the error of the result.
+package csvmo
+
+import (
+	csv "github.com/samber/go-safe-csv-writer"
+	"github.com/samber/mo"
+)
+
+// WriteR writes a single record to w using [csv.SafeWriter.Write]. The
+// result holds the number of records written by w so far, header included.
+func WriteR(w *csv.SafeWriter, record []string) mo.Result[int] {
+	if err := w.Write(record); err != nil {
+		return mo.Err[int](err)
+	}
+	return mo.Ok(int(w.Stats().Records))
+}
+
+// WriteAllR writes records to w using [csv.SafeWriter.WriteAll]. The result
+// holds the number of records written from records.
+func WriteAllR(w *csv.SafeWriter, records [][]string) mo.Result[int] {
+	before := w.Stats().Records
+	if err := w.WriteAll(records); err != nil {
+		return mo.Err[int](err)
+	}
+	return mo.Ok(int(w.Stats().Records - before))
+}
@@ -0,0 +1,64 @@
+package csvmo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	csv "github.com/samber/go-safe-csv-writer"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteR(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := csv.NewSafeWriter(&buff, csv.EscapeAll)
+	w.Columns = []csv.ColumnOpts{{Required: true}}
+
+	is.Equal(1, WriteR(w, []string{"=a"}).MustGet())
+	is.Equal(2, WriteR(w, []string{"b"}).MustGet())
+
+	result := WriteR(w, []string{""})
+	is.True(result.IsError())
+
+	var columnErr *csv.ColumnError
+	is.True(errors.As(result.Error(), &columnErr))
+	is.Equal(2, int(columnErr.Row))
+	is.ErrorIs(result.Error(), csv.ErrRequiredField)
+}
+
+func TestWriteAllR(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := csv.NewSafeWriter(&buff, csv.EscapeAll)
+	w.Columns = []csv.ColumnOpts{{Required: true}}
+
+	is.Equal(2, WriteAllR(w, [][]string{{"a"}, {"b"}}).MustGet())
+	is.Equal(1, WriteAllR(w, [][]string{{"c"}}).MustGet())
+	is.Equal("a\nb\nc\n", buff.String())
+
+	result := WriteAllR(w, [][]string{{"d"}, {""}})
+	is.ErrorIs(result.Error(), csv.ErrRequiredField)
+}
+
+func TestWriteStructOption(t *testing.T) {
+	is := assert.New(t)
+
+	type user struct {
+		ID    int               `csv:"id"`
+		Email mo.Option[string] `csv:"email"`
+	}
+
+	var buff strings.Builder
+	w := csv.NewSafeWriter(&buff, csv.EscapeAll)
+	w.Columns = []csv.ColumnOpts{{}, {Null: csv.NullAsValue, NullValue: "NULL"}}
+	is.NoError(w.WriteHeader([]string{"id", "email"}))
+	is.NoError(w.WriteStruct(user{ID: 1, Email: mo.Some("=a@example.com")}))
+	is.NoError(w.WriteStruct(user{ID: 2, Email: mo.None[string]()}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,email\n1,\" =a@example.com\"\n2,NULL\n", buff.String())
+}
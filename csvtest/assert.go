@@ -0,0 +1,69 @@
+package csvtest
+
+import (
+	stdcsv "encoding/csv"
+	"io"
+)
+
+// TestingT is the subset of [testing.TB] used by the assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// formulaTriggers are the leading characters making spreadsheet software
+// evaluate a cell.
+const formulaTriggers = "=+-@"
+
+// AssertNoFormulaTriggers parses the CSV read from r and reports every cell
+// starting with =, +, - or @. It returns whether the output is free of them.
+func AssertNoFormulaTriggers(t TestingT, r io.Reader) bool {
+	t.Helper()
+
+	reader := stdcsv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	ok := true
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return ok
+		}
+		if err != nil {
+			t.Errorf("csvtest: cannot parse output: %v", err)
+			return false
+		}
+
+		for column, field := range record {
+			if field != "" && contains(formulaTriggers, field[0]) {
+				t.Errorf("csvtest: record %d, column %d: cell starts with %q: %q", row, column, field[0], field)
+				ok = false
+			}
+		}
+	}
+}
+
+// AssertParsesWith reports whether the CSV read from r is parsed by
+// [encoding/csv] using comma as the field delimiter, with the same number of
+// fields in every record.
+func AssertParsesWith(t TestingT, r io.Reader, comma rune) bool {
+	t.Helper()
+
+	reader := stdcsv.NewReader(r)
+	reader.Comma = comma
+
+	if _, err := reader.ReadAll(); err != nil {
+		t.Errorf("csvtest: cannot parse output with delimiter %q: %v", comma, err)
+		return false
+	}
+	return true
+}
+
+func contains(set string, c byte) bool {
+	for i := 0; i < len(set); i++ {
+		if set[i] == c {
+			return true
+		}
+	}
+	return false
+}
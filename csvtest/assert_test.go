@@ -0,0 +1,54 @@
+package csvtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	csv "github.com/samber/go-safe-csv-writer"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNoFormulaTriggers(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(AssertNoFormulaTriggers(t, strings.NewReader("a,\" =b\"\nc\n")))
+
+	var buff strings.Builder
+	w := csv.NewSafeWriter(&buff, csv.EscapeAll)
+	is.NoError(w.WriteAll([][]string{{"=1+1", "+1", "-1", "@A1"}}))
+	is.True(AssertNoFormulaTriggers(t, strings.NewReader(buff.String())))
+
+	ft := &fakeT{}
+	is.False(AssertNoFormulaTriggers(ft, strings.NewReader("a,=b\n\"@c\",-1,+\n")))
+	is.Equal([]string{
+		`csvtest: record 0, column 1: cell starts with '=': "=b"`,
+		`csvtest: record 1, column 0: cell starts with '@': "@c"`,
+		`csvtest: record 1, column 1: cell starts with '-': "-1"`,
+		`csvtest: record 1, column 2: cell starts with '+': "+"`,
+	}, ft.errors)
+
+	ft = &fakeT{}
+	is.False(AssertNoFormulaTriggers(ft, strings.NewReader("a,\"b")))
+	is.Len(ft.errors, 1)
+}
+
+func TestAssertParsesWith(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(AssertParsesWith(t, strings.NewReader("a;b\n\"c;d\";e\n"), ';'))
+
+	ft := &fakeT{}
+	is.False(AssertParsesWith(ft, strings.NewReader("a;b\nc\n"), ';'))
+	is.Equal([]string{`csvtest: cannot parse output with delimiter ';': record on line 2: wrong number of fields`}, ft.errors)
+}
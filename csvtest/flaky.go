@@ -0,0 +1,79 @@
+// Package csvtest provides helpers to test code using
+// github.com/samber/go-safe-csv-writer.
+package csvtest
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrInjected is the error returned by a [FlakyWriter] by default.
+var ErrInjected = errors.New("csvtest: injected write error")
+
+// A FlakyWriter forwards writes to an [io.Writer] until a configured fault
+// occurs, to test error paths deterministically.
+type FlakyWriter struct {
+	// Err is the error returned when a fault occurs (ErrInjected when nil).
+	Err error
+
+	w          io.Writer
+	afterBytes int64 // Bytes accepted in total, negative when unlimited
+	onWrite    int   // Index of the failing write, starting at 1
+	written    int64
+	writes     int
+}
+
+// FailAfterBytes returns a FlakyWriter accepting n bytes in total. The write
+// crossing the limit is short, and every later write fails.
+// A nil w discards the data.
+func FailAfterBytes(w io.Writer, n int64) *FlakyWriter {
+	return &FlakyWriter{w: w, afterBytes: n}
+}
+
+// FailOnWrite returns a FlakyWriter failing the k-th call to Write only,
+// starting at 1, without writing anything. The next writes succeed, so that
+// retries can be tested. A nil w discards the data.
+func FailOnWrite(w io.Writer, k int) *FlakyWriter {
+	return &FlakyWriter{w: w, afterBytes: -1, onWrite: k}
+}
+
+func (f *FlakyWriter) Write(p []byte) (int, error) {
+	f.writes++
+	if f.writes == f.onWrite {
+		return 0, f.err()
+	}
+
+	var err error
+	if f.afterBytes >= 0 && f.written+int64(len(p)) > f.afterBytes {
+		p = p[:f.afterBytes-f.written]
+		err = f.err()
+	}
+
+	n := len(p)
+	if f.w != nil {
+		var werr error
+		n, werr = f.w.Write(p)
+		if werr != nil {
+			err = werr
+		}
+	}
+	f.written += int64(n)
+	return n, err
+}
+
+// Written returns the number of bytes successfully written.
+func (f *FlakyWriter) Written() int64 {
+	return f.written
+}
+
+// Writes returns the number of calls to Write, including failed ones.
+func (f *FlakyWriter) Writes() int {
+	return f.writes
+}
+
+func (f *FlakyWriter) err() error {
+	if f.Err != nil {
+		return f.Err
+	}
+	return ErrInjected
+}
@@ -0,0 +1,65 @@
+package csvtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	csv "github.com/samber/go-safe-csv-writer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailAfterBytes(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := FailAfterBytes(&buff, 5)
+
+	n, err := w.Write([]byte("abc"))
+	is.NoError(err)
+	is.Equal(3, n)
+
+	n, err = w.Write([]byte("def"))
+	is.ErrorIs(err, ErrInjected)
+	is.Equal(2, n)
+
+	n, err = w.Write([]byte("g"))
+	is.ErrorIs(err, ErrInjected)
+	is.Equal(0, n)
+
+	is.Equal("abcde", buff.String())
+	is.EqualValues(5, w.Written())
+	is.Equal(3, w.Writes())
+}
+
+func TestFailOnWrite(t *testing.T) {
+	is := assert.New(t)
+
+	errDisk := errors.New("disk full")
+	w := FailOnWrite(nil, 2)
+	w.Err = errDisk
+
+	_, err := w.Write([]byte("a"))
+	is.NoError(err)
+	n, err := w.Write([]byte("b"))
+	is.ErrorIs(err, errDisk)
+	is.Equal(0, n)
+	_, err = w.Write([]byte("c"))
+	is.NoError(err)
+
+	is.EqualValues(2, w.Written())
+	is.Equal(3, w.Writes())
+}
+
+func TestFlakyWriterSafeWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := csv.NewSafeWriter(FailAfterBytes(&buff, 10), csv.EscapeAll)
+	w.FlushThreshold = 1
+
+	is.NoError(w.Write([]string{"a", "=b"}))
+	is.ErrorIs(w.Write([]string{"c", "d"}), ErrInjected)
+	is.ErrorIs(w.Error(), ErrInjected)
+	is.Equal("a,\" =b\"\nc,", buff.String())
+}
@@ -0,0 +1,90 @@
+package csvtest
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+)
+
+// A MemFS is an in-memory [csv.FileCreator], so that tests can assert on the
+// outputs of the writers producing several files without touching the disk.
+// It is safe for concurrent use.
+type MemFS struct {
+	mu     sync.Mutex
+	files  map[string]*bytes.Buffer
+	closed map[string]bool
+}
+
+// NewMemFS returns a new empty MemFS.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		files:  map[string]*bytes.Buffer{},
+		closed: map[string]bool{},
+	}
+}
+
+// CreateFile creates the named file, truncating it if it exists.
+func (m *MemFS) CreateFile(name string) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[name] = &bytes.Buffer{}
+	m.closed[name] = false
+	return &memFile{fs: m, name: name, buf: m.files[name]}, nil
+}
+
+// Names returns the names of the files created so far, sorted.
+func (m *MemFS) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// File returns the content of the named file, and whether it exists.
+func (m *MemFS) File(name string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.files[name]
+	if !ok {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// Closed reports whether the named file has been closed.
+func (m *MemFS) Closed(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.closed[name]
+}
+
+// memFile is a file of a MemFS.
+type memFile struct {
+	fs   *MemFS
+	name string
+	buf  *bytes.Buffer
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	return f.buf.Write(p)
+}
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	f.fs.closed[f.name] = true
+	return nil
+}
@@ -0,0 +1,31 @@
+package csvtest
+
+import (
+	"testing"
+
+	csv "github.com/samber/go-safe-csv-writer"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ csv.FileCreator = (*MemFS)(nil)
+
+func TestMemFS(t *testing.T) {
+	is := assert.New(t)
+
+	fsys := NewMemFS()
+	w := csv.NewPartitionedWriter(1, csv.EscapeAll, csv.PartitionFiles(fsys, "%s.csv"))
+	is.NoError(w.WriteHeader([]string{"id", "country"}))
+	is.NoError(w.Write([]string{"=1", "us"}))
+	is.NoError(w.Write([]string{"2", "fr"}))
+	is.False(fsys.Closed("fr.csv"))
+	is.NoError(w.Close())
+
+	is.Equal([]string{"fr.csv", "us.csv"}, fsys.Names())
+	content, ok := fsys.File("us.csv")
+	is.True(ok)
+	is.Equal("id,country\n\" =1\",us\n", content)
+	is.True(fsys.Closed("fr.csv"))
+
+	_, ok = fsys.File("de.csv")
+	is.False(ok)
+}
@@ -0,0 +1,90 @@
+package csvtest
+
+import (
+	csv "github.com/samber/go-safe-csv-writer"
+)
+
+// A Sanitization reports a field altered by sanitization.
+type Sanitization struct {
+	Row       int    // Index of the record, starting at 0
+	Column    int    // Index of the field in the record, starting at 0
+	Original  string // Value given to Write
+	Sanitized string // Value as it would be written
+}
+
+// A Recorder is an in-memory [csv.RecordWriter] capturing records as a
+// [csv.SafeWriter] would write them, so that tests can assert on exports
+// without parsing CSV.
+//
+// Columns, headers and quoting are not taken into account: fields are
+// sanitized with [csv.SafetyOpts.Sanitize].
+type Recorder struct {
+	// Err, when not nil, is returned by Write and Error.
+	Err error
+
+	opts          csv.SafetyOpts
+	records       [][]string
+	originals     [][]string
+	sanitizations []Sanitization
+	flushes       int
+}
+
+// NewRecorder returns a new Recorder sanitizing fields according to opts.
+func NewRecorder(opts csv.SafetyOpts) *Recorder {
+	return &Recorder{opts: opts}
+}
+
+// Write records a copy of record.
+func (r *Recorder) Write(record []string) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	row := len(r.records)
+	sanitized := make([]string, len(record))
+	for i, field := range record {
+		sanitized[i] = r.opts.Sanitize(field)
+		if sanitized[i] != field {
+			r.sanitizations = append(r.sanitizations, Sanitization{
+				Row:       row,
+				Column:    i,
+				Original:  field,
+				Sanitized: sanitized[i],
+			})
+		}
+	}
+
+	r.records = append(r.records, sanitized)
+	r.originals = append(r.originals, append([]string{}, record...))
+	return nil
+}
+
+// Flush counts a call to Flush.
+func (r *Recorder) Flush() {
+	r.flushes++
+}
+
+// Error returns [Recorder.Err].
+func (r *Recorder) Error() error {
+	return r.Err
+}
+
+// Records returns the records written, sanitized.
+func (r *Recorder) Records() [][]string {
+	return r.records
+}
+
+// Originals returns the records written, as given to Write.
+func (r *Recorder) Originals() [][]string {
+	return r.originals
+}
+
+// Sanitizations returns the fields altered by sanitization, in order.
+func (r *Recorder) Sanitizations() []Sanitization {
+	return r.sanitizations
+}
+
+// Flushes returns the number of calls to Flush.
+func (r *Recorder) Flushes() int {
+	return r.flushes
+}
@@ -0,0 +1,40 @@
+package csvtest
+
+import (
+	"errors"
+	"testing"
+
+	csv "github.com/samber/go-safe-csv-writer"
+	"github.com/stretchr/testify/assert"
+)
+
+// export is the kind of application code tested with a Recorder.
+func export(w csv.RecordWriter, names []string) error {
+	for _, name := range names {
+		if err := w.Write([]string{name}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func TestRecorder(t *testing.T) {
+	is := assert.New(t)
+
+	r := NewRecorder(csv.EscapeAll)
+	is.NoError(export(r, []string{"alice", "=bob", "-carol"}))
+	is.Equal([][]string{{"alice"}, {" =bob"}, {" -carol"}}, r.Records())
+	is.Equal([][]string{{"alice"}, {"=bob"}, {"-carol"}}, r.Originals())
+	is.Equal([]Sanitization{
+		{Row: 1, Column: 0, Original: "=bob", Sanitized: " =bob"},
+		{Row: 2, Column: 0, Original: "-carol", Sanitized: " -carol"},
+	}, r.Sanitizations())
+	is.Equal(1, r.Flushes())
+
+	errBoom := errors.New("boom")
+	r = NewRecorder(csv.EscapeAll)
+	r.Err = errBoom
+	is.ErrorIs(export(r, []string{"alice"}), errBoom)
+	is.Empty(r.Records())
+}
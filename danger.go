@@ -0,0 +1,71 @@
+package csv
+
+// A Reason tells why a field is dangerous, as reported by [IsDangerous].
+type Reason int
+
+const (
+	// ReasonNone is the reason of fields that are not dangerous.
+	ReasonNone Reason = iota
+	// ReasonFormula is a field starting with a character that spreadsheet
+	// software interprets as the beginning of a formula.
+	ReasonFormula
+	// ReasonLongNumber is a digit string long enough to be rendered in
+	// scientific notation by spreadsheet software.
+	ReasonLongNumber
+	// ReasonUNCPath is a field starting with a UNC path, such as
+	// \\server\share.
+	ReasonUNCPath
+	// ReasonFileURL is a field starting with a file:// URL.
+	ReasonFileURL
+	// ReasonCustom is a field changed by [SafetyOpts.SanitizeFunc].
+	ReasonCustom
+)
+
+// String returns the name of the reason.
+func (r Reason) String() string {
+	switch r {
+	case ReasonFormula:
+		return "formula"
+	case ReasonLongNumber:
+		return "long-number"
+	case ReasonUNCPath:
+		return "unc-path"
+	case ReasonFileURL:
+		return "file-url"
+	case ReasonCustom:
+		return "custom"
+	default:
+		return "none"
+	}
+}
+
+// IsDangerous reports whether a [SafeWriter] using opts would sanitize field,
+// and why. It applies the rules of the writer to values at input time, so
+// that form submissions or API payloads can be rejected or flagged before
+// they are stored.
+func IsDangerous(field string, opts SafetyOpts) (bool, Reason) {
+	if opts.SanitizeFunc != nil {
+		if sanitized, ok := opts.SanitizeFunc(field, -1); ok {
+			if sanitized != field {
+				return true, ReasonCustom
+			}
+			if opts.isLongNumber(field) {
+				return true, ReasonLongNumber
+			}
+			return false, ReasonNone
+		}
+	}
+	if c, ok := opts.trigger(field); ok {
+		switch {
+		case opts.isTrigger(c):
+			return true, ReasonFormula
+		case c == '\\':
+			return true, ReasonUNCPath
+		}
+		return true, ReasonFileURL
+	}
+	if opts.isLongNumber(field) {
+		return true, ReasonLongNumber
+	}
+	return false, ReasonNone
+}
@@ -0,0 +1,99 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDangerous(t *testing.T) {
+	is := assert.New(t)
+
+	opts := EscapeAll
+	opts.LongNumberDigits = 12
+
+	for field, expected := range map[string]Reason{
+		"":                 ReasonNone,
+		"hello":            ReasonNone,
+		"a=b":              ReasonNone,
+		"12345":            ReasonNone,
+		"=SUM(A1:A2)":      ReasonFormula,
+		"+33123456789":     ReasonFormula,
+		"-1":               ReasonFormula,
+		"@cmd":             ReasonFormula,
+		"\tx":              ReasonFormula,
+		"4111111111111111": ReasonLongNumber,
+	} {
+		dangerous, reason := IsDangerous(field, opts)
+		is.Equal(expected, reason, field)
+		is.Equal(expected != ReasonNone, dangerous, field)
+		is.Equal(dangerous, opts.Sanitize(field) != field, field)
+	}
+
+	opts = SafetyOpts{EscapeCharEqual: true, TriggerRunes: []rune{'|', '＝', 'f'}}
+	for field, expected := range map[string]Reason{
+		"|cmd":     ReasonFormula,
+		"＝1+1":     ReasonFormula,
+		"file://x": ReasonFormula,
+		"%x":       ReasonNone,
+	} {
+		dangerous, reason := IsDangerous(field, opts)
+		is.Equal(expected, reason, field)
+		is.Equal(expected != ReasonNone, dangerous, field)
+	}
+	is.Equal([]rune{'=', '|', '＝', 'f'}, opts.Triggers())
+	is.Equal([]rune{'=', '+', '-', '@', '\t', '\n', '\r', '|', '%'}, EscapeAll.Triggers())
+	is.Equal([]rune{'='}, SafetyOpts{EscapeCharEqual: true, TriggerRunes: []rune{'='}}.Triggers())
+	is.Empty(SafetyOpts{}.Triggers())
+
+	dangerous, reason := IsDangerous("-1", SafetyOpts{EscapeCharEqual: true})
+	is.False(dangerous)
+	is.Equal(ReasonNone, reason)
+
+	opts = SafetyOpts{EscapeUNCPaths: true, EscapeFileURLs: true}
+	for field, expected := range map[string]Reason{
+		`\\server\share\x.xlsx`: ReasonUNCPath,
+		`\x`:                    ReasonNone,
+		"file:///etc/passwd":    ReasonFileURL,
+		"FILE://host/share":     ReasonFileURL,
+		"file:/x":               ReasonNone,
+		"see file://x":          ReasonNone,
+	} {
+		_, reason := IsDangerous(field, opts)
+		is.Equal(expected, reason, field)
+	}
+
+	dangerous, reason = IsDangerous(`\\server\share`, EscapeAll)
+	is.False(dangerous)
+	is.Equal(ReasonNone, reason)
+
+	opts = EscapeAll
+	opts.SanitizeFunc = func(field string, col int) (string, bool) {
+		switch {
+		case field == "secret":
+			return "[redacted]", true
+		case strings.HasPrefix(field, "=A"):
+			return field, true
+		}
+		return "", false
+	}
+	for field, expected := range map[string]Reason{
+		"secret": ReasonCustom,
+		"=A1":    ReasonNone,
+		"=SUM()": ReasonFormula,
+		"@cmd":   ReasonFormula,
+		"hello":  ReasonNone,
+	} {
+		dangerous, reason := IsDangerous(field, opts)
+		is.Equal(expected, reason, field)
+		is.Equal(dangerous, opts.Sanitize(field) != field, field)
+	}
+
+	is.Equal("none", ReasonNone.String())
+	is.Equal("formula", ReasonFormula.String())
+	is.Equal("long-number", ReasonLongNumber.String())
+	is.Equal("unc-path", ReasonUNCPath.String())
+	is.Equal("file-url", ReasonFileURL.String())
+	is.Equal("custom", ReasonCustom.String())
+}
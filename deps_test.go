@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const modulePath = "github.com/samber/go-safe-csv-writer"
+
+// TestNoThirdPartyImports checks that the library packages only import the
+// standard library, whatever the build tags. Optional subsystems depending
+// on other modules live in their own module, like csvmo, or under cmd/.
+func TestNoThirdPartyImports(t *testing.T) {
+	is := assert.New(t)
+
+	for _, dir := range []string{".", "csvtest"} {
+		entries, err := os.ReadDir(dir)
+		must(err)
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+
+			f, err := parser.ParseFile(token.NewFileSet(), dir+"/"+name, nil, parser.ImportsOnly)
+			must(err)
+
+			for _, spec := range f.Imports {
+				path, err := strconv.Unquote(spec.Path.Value)
+				must(err)
+				if path == modulePath || strings.HasPrefix(path, modulePath+"/") {
+					continue
+				}
+				is.NotContains(strings.SplitN(path, "/", 2)[0], ".", "%s/%s imports %s", dir, name, path)
+			}
+		}
+	}
+}
+
+// TestNoReflectDeps checks that builds without reflection leave out the
+// heavy packages of the optional subsystems. reflect itself is still pulled
+// in by fmt.
+func TestNoReflectDeps(t *testing.T) {
+	is := assert.New(t)
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	out, err := exec.Command("go", "list", "-deps", "-tags", "safecsv_noreflect", ".").Output()
+	must(err)
+
+	deps := strings.Fields(string(out))
+	for _, path := range []string{"net/http", "crypto/tls", "expvar", "runtime/pprof"} {
+		is.NotContains(deps, path)
+	}
+	is.Contains(deps, modulePath)
+}
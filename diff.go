@@ -0,0 +1,74 @@
+package csv
+
+import "io"
+
+// DiffKind describes how a cell differs between two inputs.
+type DiffKind int
+
+const (
+	// CellChanged is a cell present in both inputs, with different values.
+	CellChanged DiffKind = iota
+	// CellRemoved is a cell of the original input missing from the
+	// sanitized one.
+	CellRemoved
+	// CellAdded is a cell of the sanitized input missing from the
+	// original one.
+	CellAdded
+)
+
+// A CellDiff reports a cell that differs between two inputs.
+type CellDiff struct {
+	Row       int64    // Index of the record, starting at 0
+	Column    int      // Index of the field in the record, starting at 0
+	Kind      DiffKind // How the cell differs
+	Original  string   // Value in the original input, empty when added
+	Sanitized string   // Value in the sanitized input, empty when removed
+}
+
+// Diff compares two CSV inputs cell by cell, typically an export and its
+// sanitized version, and returns the cells that differ, in order. Inputs
+// are read leniently, like [SafeCopy] does.
+func Diff(original, sanitized io.Reader) ([]CellDiff, error) {
+	a := newLenientReader(original, ',')
+	b := newLenientReader(sanitized, ',')
+
+	var diffs []CellDiff
+	for row := int64(0); ; row++ {
+		left, err := readOrEOF(a)
+		if err != nil {
+			return nil, err
+		}
+		right, err := readOrEOF(b)
+		if err != nil {
+			return nil, err
+		}
+		if left == nil && right == nil {
+			return diffs, nil
+		}
+
+		for n := 0; n < len(left) || n < len(right); n++ {
+			diff := CellDiff{Row: row, Column: n}
+			switch {
+			case n >= len(right):
+				diff.Kind, diff.Original = CellRemoved, left[n]
+			case n >= len(left):
+				diff.Kind, diff.Sanitized = CellAdded, right[n]
+			case left[n] != right[n]:
+				diff.Kind, diff.Original, diff.Sanitized = CellChanged, left[n], right[n]
+			default:
+				continue
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+}
+
+// readOrEOF reads a record from r, returning a nil record at the end of the
+// input.
+func readOrEOF(r *SafeReader) ([]string, error) {
+	record, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	return record, err
+}
@@ -0,0 +1,32 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	is := assert.New(t)
+
+	original := "id,name\n1,=A1\n2,ok,extra\n3,-1\n"
+	sanitized := "id,name\n1,\" =A1\"\n2,ok\n"
+
+	diffs, err := Diff(strings.NewReader(original), strings.NewReader(sanitized))
+	is.NoError(err)
+	is.Equal([]CellDiff{
+		{Row: 1, Column: 1, Kind: CellChanged, Original: "=A1", Sanitized: " =A1"},
+		{Row: 2, Column: 2, Kind: CellRemoved, Original: "extra"},
+		{Row: 3, Column: 0, Kind: CellRemoved, Original: "3"},
+		{Row: 3, Column: 1, Kind: CellRemoved, Original: "-1"},
+	}, diffs)
+
+	diffs, err = Diff(strings.NewReader("a\n"), strings.NewReader("a\nb\n"))
+	is.NoError(err)
+	is.Equal([]CellDiff{{Row: 1, Column: 0, Kind: CellAdded, Sanitized: "b"}}, diffs)
+
+	diffs, err = Diff(strings.NewReader(original), strings.NewReader(original))
+	is.NoError(err)
+	is.Empty(diffs)
+}
@@ -0,0 +1,61 @@
+package csv
+
+import "io"
+
+// asyncWriter writes to w on a separate goroutine, one buffer at a time, so
+// that the output buffer of a [SafeWriter] can be filled with the next
+// records while the previous ones are being written.
+type asyncWriter struct {
+	w    io.Writer
+	buf  []byte        // Data being written by the goroutine
+	done chan struct{} // Closed once the pending write is done, nil if none
+	err  error
+}
+
+// Write waits for the pending write, then starts writing a copy of p. It
+// returns the error of a previous write, if any.
+func (a *asyncWriter) Write(p []byte) (int, error) {
+	if err := a.wait(); err != nil {
+		return 0, err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	a.buf = append(a.buf[:0], p...)
+	a.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		var n int
+		n, a.err = a.w.Write(a.buf)
+		if a.err == nil && n < len(a.buf) {
+			a.err = io.ErrShortWrite
+		}
+	}(a.done)
+	return len(p), nil
+}
+
+// wait waits for the pending write, if any, and returns the first error
+// encountered.
+func (a *asyncWriter) wait() error {
+	if a.done != nil {
+		<-a.done
+		a.done = nil
+	}
+	return a.err
+}
+
+// flush flushes the output buffer, waits for the pending write of a
+// double-buffered writer, then flushes the underlying writer if
+// [SafeWriter.FlushHTTP] is set.
+func (w *SafeWriter) flush() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.async != nil {
+		if err := w.async.wait(); err != nil {
+			return err
+		}
+	}
+	return w.flushHTTP()
+}
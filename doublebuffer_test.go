@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gateWriter blocks each write until a value is sent on gate.
+type gateWriter struct {
+	strings.Builder
+	gate chan struct{}
+}
+
+func (g *gateWriter) Write(p []byte) (int, error) {
+	<-g.gate
+	return g.Builder.Write(p)
+}
+
+func TestSafeWriterDoubleBuffer(t *testing.T) {
+	is := assert.New(t)
+
+	dst := &gateWriter{gate: make(chan struct{})}
+	w := NewSafeWriter(dst, EscapeAll)
+	w.DoubleBuffer = true
+	w.FlushThreshold = 10
+
+	// The first record is handed over to the blocked writer, and the next
+	// ones are encoded meanwhile.
+	is.NoError(w.Write([]string{"aaaaaaaaa"}))
+	is.NoError(w.Write([]string{"=b"}))
+	is.NoError(w.Write([]string{"c"}))
+
+	go func() {
+		dst.gate <- struct{}{}
+		dst.gate <- struct{}{}
+	}()
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("aaaaaaaaa\n\" =b\"\nc\n", dst.String())
+
+	go func() { dst.gate <- struct{}{} }()
+	is.NoError(w.WriteAll([][]string{{"d"}}))
+	is.Equal("aaaaaaaaa\n\" =b\"\nc\nd\n", dst.String())
+}
+
+func TestSafeWriterDoubleBufferError(t *testing.T) {
+	is := assert.New(t)
+
+	w := NewSafeWriter(&errorWriter{}, EscapeAll)
+	w.DoubleBuffer = true
+	w.FlushThreshold = 1
+
+	is.NoError(w.Write([]string{"a"}))
+	is.EqualError(w.Error(), "Test")
+	is.EqualError(w.Write([]string{"b"}), "Test")
+	is.EqualError(w.WriteAll([][]string{{"c"}}), "Test")
+}
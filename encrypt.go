@@ -0,0 +1,117 @@
+package csv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix starts the fields encrypted by [Encrypt] and
+// [EncryptDeterministic].
+const encryptedPrefix = "enc:"
+
+// ErrNotEncrypted is returned by the transformer of [Decrypt] for fields that
+// were not encrypted.
+var ErrNotEncrypted = errors.New("csv: field is not encrypted")
+
+// Encrypt returns a [Transformer] encrypting every non-empty field with
+// AES-GCM, as "enc:" followed by the base64 encoding of a random nonce and
+// the ciphertext. The key must be 16, 24 or 32 bytes long, to select
+// AES-128, AES-192 or AES-256. Encrypting the same value twice gives
+// different fields, which reveals nothing about the values.
+func Encrypt(key []byte) (Transformer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		return seal(aead, nonce, field), nil
+	}, nil
+}
+
+// EncryptDeterministic returns a [Transformer] encrypting fields like
+// [Encrypt], except that the nonce is derived from the value, so that equal
+// values give equal fields and encrypted columns can still be joined or
+// grouped. This reveals which fields hold the same value.
+func EncryptDeterministic(key []byte) (Transformer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The nonce is an HMAC of the value with a key derived from key, so
+	// that two distinct values never share a nonce.
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("safecsv deterministic nonce"))
+	nonceKey := mac.Sum(nil)
+
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+
+		mac := hmac.New(sha256.New, nonceKey)
+		mac.Write([]byte(field))
+		return seal(aead, mac.Sum(nil)[:aead.NonceSize()], field), nil
+	}, nil
+}
+
+// Decrypt returns a [Transformer] decrypting the fields encrypted by
+// [Encrypt] or [EncryptDeterministic] with key. Empty fields are left
+// untouched, and other fields are rejected with [ErrNotEncrypted].
+func Decrypt(key []byte) (Transformer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+		if !strings.HasPrefix(field, encryptedPrefix) {
+			return "", ErrNotEncrypted
+		}
+
+		data, err := base64.StdEncoding.DecodeString(field[len(encryptedPrefix):])
+		if err != nil || len(data) < aead.NonceSize() {
+			return "", ErrNotEncrypted
+		}
+
+		plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	}, nil
+}
+
+// newAEAD returns the AES-GCM cipher of key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts field with nonce, and encodes the result.
+func seal(aead cipher.AEAD, nonce []byte, field string) string {
+	data := aead.Seal(nonce, nonce, []byte(field), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,68 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	is := assert.New(t)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	encrypt, err := Encrypt(key)
+	is.NoError(err)
+	decrypt, err := Decrypt(key)
+	is.NoError(err)
+
+	a := transformed(t, encrypt, "alice@example.com")
+	b := transformed(t, encrypt, "alice@example.com")
+	is.True(strings.HasPrefix(a, "enc:"))
+	is.NotEqual(a, b)
+	is.Equal("alice@example.com", transformed(t, decrypt, a))
+	is.Equal("alice@example.com", transformed(t, decrypt, b))
+	is.Equal("", transformed(t, encrypt, ""))
+	is.Equal("", transformed(t, decrypt, ""))
+
+	_, err = decrypt("alice@example.com")
+	is.ErrorIs(err, ErrNotEncrypted)
+	_, err = decrypt("enc:!!")
+	is.ErrorIs(err, ErrNotEncrypted)
+
+	other, err := Decrypt([]byte("fedcba9876543210"))
+	is.NoError(err)
+	_, err = other(a)
+	is.Error(err)
+
+	_, err = Encrypt([]byte("short"))
+	is.Error(err)
+	_, err = EncryptDeterministic(nil)
+	is.Error(err)
+	_, err = Decrypt(nil)
+	is.Error(err)
+}
+
+func TestEncryptDeterministic(t *testing.T) {
+	is := assert.New(t)
+
+	key := []byte("0123456789abcdef")
+
+	encrypt, err := EncryptDeterministic(key)
+	is.NoError(err)
+	decrypt, err := Decrypt(key)
+	is.NoError(err)
+
+	a := transformed(t, encrypt, "alice")
+	is.Equal(a, transformed(t, encrypt, "alice"))
+	is.NotEqual(a, transformed(t, encrypt, "bob"))
+	is.Equal("alice", transformed(t, decrypt, a))
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {Transformers: []Transformer{encrypt}}}
+	is.NoError(w.Write([]string{"1", "alice"}))
+	w.Flush()
+	is.Equal("1,"+a+"\n", buff.String())
+}
@@ -0,0 +1,87 @@
+package csv
+
+import (
+	"strings"
+	"text/template"
+	"time"
+)
+
+// An Envelope declares lines written around the records of a [SafeWriter],
+// as required by many bank and EDI flat-file formats: the header lines come
+// before the first record, header row included, and the footer lines are
+// written by [SafeWriter.Close], after the trailer if any.
+//
+// Lines are [text/template] templates executed with an [EnvelopeData], such
+// as:
+//
+//	HDR{{.Date.Format "20060102"}}{{printf "%04d" .Sequence}}
+//	FTR{{printf "%08d" .Records}}
+//
+// Lines without actions are written as is. Lines are neither quoted nor
+// sanitized, so they must not hold untrusted data.
+type Envelope struct {
+	Header []string
+	Footer []string
+
+	// Sequence is the number of the file, such as its index in a daily
+	// series.
+	Sequence int
+	// Date is the date of the file, the time of the first write when zero.
+	Date time.Time
+}
+
+// EnvelopeData is the data of the templates of an [Envelope]. The counters
+// of TrailerInfo are zero in header lines.
+type EnvelopeData struct {
+	TrailerInfo
+	Date     time.Time
+	Sequence int
+}
+
+// appendEnvelope appends the lines to the scratch buffer.
+func (w *SafeWriter) appendEnvelope(lines []string, info TrailerInfo) error {
+	data := EnvelopeData{TrailerInfo: info, Date: w.Envelope.Date, Sequence: w.Envelope.Sequence}
+	if data.Date.IsZero() {
+		data.Date = w.opened
+	}
+
+	for _, line := range lines {
+		if strings.Contains(line, "{{") {
+			tmpl, err := template.New("envelope").Parse(line)
+			if err != nil {
+				return err
+			}
+
+			var b strings.Builder
+			if err := tmpl.Execute(&b, data); err != nil {
+				return err
+			}
+			line = b.String()
+		}
+		w.buf = append(w.buf, line...)
+		w.buf = append(w.buf, w.lineEnd()...)
+	}
+	return nil
+}
+
+// openEnvelope appends the header lines of the envelope to the scratch
+// buffer, once, before the first record.
+func (w *SafeWriter) openEnvelope() error {
+	if w.Envelope == nil || !w.opened.IsZero() {
+		return nil
+	}
+	w.opened = time.Now()
+	return w.appendEnvelope(w.Envelope.Header, TrailerInfo{})
+}
+
+// writeEnvelope writes lines, opening the envelope first if needed.
+func (w *SafeWriter) writeEnvelope(lines []string) error {
+	w.buf = w.buf[:0]
+	if err := w.openEnvelope(); err != nil {
+		return err
+	}
+	if err := w.appendEnvelope(lines, w.trailerInfo()); err != nil {
+		return err
+	}
+	return w.writeBuffer()
+}
@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.UseCRLF = true
+	w.Trailer = CountTrailer("TRL")
+	w.Envelope = &Envelope{
+		Header:   []string{"BANKEXPORT v2", `HDR{{.Date.Format "20060102"}}{{printf "%04d" .Sequence}}`},
+		Footer:   []string{`EOF{{printf "%06d" .Records}}`},
+		Sequence: 7,
+		Date:     time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+	}
+	is.NoError(w.WriteHeader([]string{"iban", "amount"}))
+	is.NoError(w.WriteAll([][]string{{"FR76", "-10"}, {"DE89", "20"}}))
+	is.NoError(w.Close())
+	is.Equal("BANKEXPORT v2\r\nHDR202610140007\r\niban,amount\r\nFR76,\" -10\"\r\nDE89,20\r\nTRL,2\r\nEOF000002\r\n", buff.String())
+
+	// The footer is written once.
+	is.NoError(w.Close())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Envelope = &Envelope{Header: []string{"H"}, Footer: []string{"F{{.Records}}"}}
+	is.NoError(w.WriteAllParallel([][]string{{"a"}, {"b"}, {"c"}}, ParallelOpts{ChunkSize: 1}))
+	is.NoError(w.Close())
+	is.Equal("H\na\nb\nc\nF3\n", buff.String())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Envelope = &Envelope{Header: []string{"H{{.Date.Year}}"}, Footer: []string{"F"}}
+	is.NoError(w.Close())
+	is.Equal("H"+time.Now().Format("2006")+"\nF\n", buff.String())
+
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Envelope = &Envelope{Header: []string{"{{.Missing}}"}}
+	is.Error(w.Write([]string{"a"}))
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Envelope = &Envelope{Footer: []string{"{{"}}
+	is.Error(w.Close())
+}
@@ -0,0 +1,104 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrValueNotAllowed is returned when a field is not part of the
+	// allowed values of its column.
+	ErrValueNotAllowed = errors.New("value not allowed")
+	// ErrRequiredField is returned when a required column is empty.
+	ErrRequiredField = errors.New("required field is empty")
+	// ErrTypeMismatch is returned when a field does not match the type of
+	// its column.
+	ErrTypeMismatch = errors.New("value does not match column type")
+	// ErrDuplicateValue is returned when a unique column holds a value
+	// already written.
+	ErrDuplicateValue = errors.New("duplicate value")
+	// ErrUniqueLimit is returned when a unique column holds more distinct
+	// values than its limit.
+	ErrUniqueLimit = errors.New("too many values to check uniqueness")
+	// ErrDangerousField is returned with [StrategyReject] when a field
+	// starts with a trigger, wrapped in a [ColumnError].
+	ErrDangerousField = errors.New("field would be interpreted as a formula")
+	// ErrListElement is returned when an element of a list written with
+	// [ListJoined] contains the list separator.
+	ErrListElement = errors.New("list element contains the separator")
+	// ErrBinaryField is returned with [BinaryReject] when a field holds
+	// binary data.
+	ErrBinaryField = errors.New("field holds binary data")
+	// ErrTooManyErrors is returned once more records have been rejected
+	// than [SafeWriter.MaxErrors].
+	ErrTooManyErrors = errors.New("csv: too many rejected records")
+)
+
+// A ColumnError is returned when a field cannot be written. It locates the
+// field in the output.
+type ColumnError struct {
+	Row    int64  // Index of the record in the output, starting at 0
+	Column int    // Index of the field in the record, starting at 0
+	Name   string // Name of the column, if known
+	Value  string // Offending value
+	Err    error  // The actual error
+
+	// Metadata of the writer, see [SafeWriter.Metadata].
+	Metadata map[string]string
+}
+
+func (e *ColumnError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("csv: record %d, column %d (%q): %v%s", e.Row, e.Column, e.Name, e.Err, formatMetadata(e.Metadata))
+	}
+	return fmt.Sprintf("csv: record %d, column %d: %v%s", e.Row, e.Column, e.Err, formatMetadata(e.Metadata))
+}
+
+func (e *ColumnError) Unwrap() error { return e.Err }
+
+// reject counts record, rejected with err, writes it to the quarantine,
+// and aborts the writer once [SafeWriter.MaxErrors] is exceeded.
+func (w *SafeWriter) reject(record []string, err error) error {
+	w.stats.RejectedRecords++
+	qerr := w.quarantine(record, err)
+
+	if w.MaxErrors > 0 && w.stats.RejectedRecords > int64(w.MaxErrors) {
+		w.aborted = fmt.Errorf("%w (%d > %d), last: %v", ErrTooManyErrors, w.stats.RejectedRecords, w.MaxErrors, err)
+		return w.aborted
+	}
+	if qerr != nil {
+		return qerr
+	}
+	return err
+}
+
+// columnError returns a [ColumnError] for the field at position n of the
+// record being written.
+func (w *SafeWriter) columnError(n int, value string, err error) error {
+	return &ColumnError{
+		Row:    w.stats.Records,
+		Column: n,
+		Name:   w.columnName(n),
+		Value:  value,
+		Err:    err,
+
+		Metadata: w.Metadata,
+	}
+}
+
+// formatMetadata formats metadata as a suffix of error messages, such as
+// " (export=42, tenant=acme)", sorted by key. It returns "" when empty.
+func formatMetadata(metadata map[string]string) string {
+	if len(metadata) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return " (" + strings.Join(pairs, ", ") + ")"
+}
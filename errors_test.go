@@ -0,0 +1,38 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMetadata(t *testing.T) {
+	is := assert.New(t)
+
+	metadata := map[string]string{"tenant": "acme", "export": "42"}
+
+	w := NewSafeWriter(&strings.Builder{}, EscapeAll)
+	w.Metadata = metadata
+	w.Columns = []ColumnOpts{{Required: true}}
+
+	err := w.Write([]string{""})
+	is.EqualError(err, `csv: record 0, column 0: required field is empty (export=42, tenant=acme)`)
+	is.Equal(&ColumnError{Row: 0, Column: 0, Err: ErrRequiredField, Metadata: metadata}, err)
+
+	errBoom := errors.New("boom")
+	p := NewPipeline(NewSafeReader(strings.NewReader("a\n")), w, Stage{
+		Name:   "fail",
+		Record: func(record []string) ([]string, error) { return nil, errBoom },
+	})
+	_, err = p.Run(context.Background())
+	is.EqualError(err, `csv: stage 0 ("fail"), record 0: boom (export=42, tenant=acme)`)
+
+	var stageErr *StageError
+	is.True(errors.As(err, &stageErr))
+	is.Equal("acme", stageErr.Metadata["tenant"])
+
+	is.Equal("", formatMetadata(nil))
+}
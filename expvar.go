@@ -0,0 +1,66 @@
+//go:build !tinygo && !safecsv_noreflect
+// +build !tinygo,!safecsv_noreflect
+
+package csv
+
+import (
+	"expvar"
+	"sync"
+)
+
+func init() {
+	features = append(features, FeatureExpvar)
+}
+
+// writerVars holds the counters published by [SafeWriter.PublishExpvar].
+type writerVars struct {
+	rows      *expvar.Int
+	bytes     *expvar.Int
+	sanitized *expvar.Int
+}
+
+// PublishExpvar publishes the counters of w with [expvar], under the names
+// prefix+".rows_written", prefix+".bytes_written" and
+// prefix+".cells_sanitized". Only the writes made after the call are
+// counted.
+//
+// Writers publishing under the same prefix share the counters, so that a
+// service creating a writer per export reports its totals. Publishing under
+// a name already used by another kind of variable panics, like
+// [expvar.Publish] does.
+func (w *SafeWriter) PublishExpvar(prefix string) {
+	w.vars = &writerVars{
+		rows:      expvarInt(prefix + ".rows_written"),
+		bytes:     expvarInt(prefix + ".bytes_written"),
+		sanitized: expvarInt(prefix + ".cells_sanitized"),
+	}
+}
+
+// addRecord counts a record written with sanitized sanitized fields.
+func (v *writerVars) addRecord(sanitized int64) {
+	v.rows.Add(1)
+	v.sanitized.Add(sanitized)
+}
+
+// addBytes counts n bytes written.
+func (v *writerVars) addBytes(n int) {
+	v.bytes.Add(int64(n))
+}
+
+// expvarMu makes the lookup and the publication of expvarInt atomic, as
+// writers sharing a prefix may be published concurrently.
+var expvarMu sync.Mutex
+
+// expvarInt returns the integer published under name, publishing it first if
+// needed.
+func expvarInt(name string) *expvar.Int {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if v := expvar.Get(name); v != nil {
+		if i, ok := v.(*expvar.Int); ok {
+			return i
+		}
+	}
+	return expvar.NewInt(name)
+}
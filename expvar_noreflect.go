@@ -0,0 +1,17 @@
+//go:build tinygo || safecsv_noreflect
+// +build tinygo safecsv_noreflect
+
+package csv
+
+// This file replaces expvar.go in builds without reflection, as expvar
+// pulls net/http in.
+
+// writerVars is unused without expvar.
+type writerVars struct{}
+
+func (v *writerVars) addRecord(sanitized int64) {}
+func (v *writerVars) addBytes(n int)            {}
+
+// PublishExpvar does nothing in builds without reflection, which do not
+// include expvar.
+func (w *SafeWriter) PublishExpvar(prefix string) {}
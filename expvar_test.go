@@ -0,0 +1,59 @@
+//go:build !tinygo && !safecsv_noreflect
+// +build !tinygo,!safecsv_noreflect
+
+package csv
+
+import (
+	"expvar"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.MustWrite([]string{"unpublished"})
+	w.PublishExpvar("test_export")
+	w.MustWrite([]string{"id", "name"})
+	w.MustWriteAll([][]string{{"1", "=a"}, {"2", "@b"}})
+
+	is.Equal("3", expvar.Get("test_export.rows_written").String())
+	is.Equal("24", expvar.Get("test_export.bytes_written").String())
+	is.Equal("2", expvar.Get("test_export.cells_sanitized").String())
+
+	w = NewSafeWriter(io.Discard, EscapeAll)
+	w.PublishExpvar("test_export")
+	is.NoError(w.WriteAllParallel([][]string{{"=c"}, {"d"}}, ParallelOpts{ChunkSize: 1}))
+	is.Equal("5", expvar.Get("test_export.rows_written").String())
+	is.Equal("32", expvar.Get("test_export.bytes_written").String())
+	is.Equal("3", expvar.Get("test_export.cells_sanitized").String())
+
+	expvar.NewString("test_string.rows_written")
+	is.Panics(func() { NewSafeWriter(io.Discard, EscapeAll).PublishExpvar("test_string") })
+	is.True(HasFeature(FeatureExpvar))
+}
+
+func TestPublishExpvarConcurrent(t *testing.T) {
+	is := assert.New(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := NewSafeWriter(io.Discard, EscapeAll)
+			w.PublishExpvar("test_concurrent")
+			w.MustWrite([]string{"a"})
+			w.Flush()
+		}()
+	}
+	wg.Wait()
+
+	is.Equal("16", expvar.Get("test_concurrent.rows_written").String())
+}
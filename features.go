@@ -0,0 +1,65 @@
+package csv
+
+import "sort"
+
+// Names of the features reported by [Features].
+const (
+	FeatureCSV        = "csv"         // CSV output, see [SafeWriter]
+	FeatureFixedWidth = "fixed-width" // Fixed-width output, see [FixedWidthWriter]
+	FeatureCRLF       = "crlf"        // \r\n line terminators
+	FeatureCanonical  = "canonical"   // Byte-reproducible output
+	FeatureAppend     = "append"      // Appending to existing files
+	FeatureParallel   = "parallel"    // Parallel encoding
+	FeaturePipeline   = "pipeline"    // Reader to writer pipelines
+
+	FeatureQuoteAuto    = "quote-auto"    // See [QuoteAuto]
+	FeatureQuoteAlways  = "quote-always"  // See [QuoteAlways]
+	FeatureQuoteMinimal = "quote-minimal" // See [QuoteMinimal]
+
+	FeatureEncodingUTF8        = "encoding-utf-8"        // UTF-8 input and output
+	FeatureEncodingUTF16       = "encoding-utf-16"       // UTF-16 input, with a byte order mark
+	FeatureEncodingWindows1252 = "encoding-windows-1252" // Windows-1252 input fallback
+	FeatureBareCR              = "bare-cr"               // Input using \r as line terminator
+
+	FeatureFormulaDetection = "formula-detection" // Findings on read, see [Finding]
+	FeatureQuarantine       = "quarantine"        // Placeholders on read
+	FeatureStructs          = "structs"           // See [SafeWriter.WriteStruct], unavailable without reflection
+	FeatureExpvar           = "expvar"            // See [SafeWriter.PublishExpvar], unavailable without reflection
+	FeatureProfileLabels    = "profile-labels"    // pprof labels of [Pipeline], unavailable without reflection
+)
+
+// features holds the features supported by this build. Optional features
+// are added by the files providing them.
+var features = []string{
+	FeatureCSV,
+	FeatureFixedWidth,
+	FeatureCRLF,
+	FeatureCanonical,
+	FeatureAppend,
+	FeatureParallel,
+	FeaturePipeline,
+	FeatureQuoteAuto,
+	FeatureQuoteAlways,
+	FeatureQuoteMinimal,
+	FeatureEncodingUTF8,
+	FeatureEncodingUTF16,
+	FeatureEncodingWindows1252,
+	FeatureBareCR,
+	FeatureFormulaDetection,
+	FeatureQuarantine,
+}
+
+// Features returns the names of the features supported by this build of the
+// package, sorted, so that applications embedding the writer can discover
+// the available modes, for instance to list them in a configuration UI.
+func Features() []string {
+	names := append([]string{}, features...)
+	sort.Strings(names)
+	return names
+}
+
+// HasFeature reports whether the named feature is supported by this build
+// of the package.
+func HasFeature(name string) bool {
+	return contains(features, name)
+}
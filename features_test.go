@@ -0,0 +1,23 @@
+package csv
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures(t *testing.T) {
+	is := assert.New(t)
+
+	names := Features()
+	is.True(sort.StringsAreSorted(names))
+	is.Contains(names, FeatureFixedWidth)
+	is.Contains(names, FeatureQuoteMinimal)
+
+	names[0] = "changed"
+	is.NotContains(Features(), "changed")
+
+	is.True(HasFeature(FeatureCanonical))
+	is.False(HasFeature("xlsx"))
+}
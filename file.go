@@ -0,0 +1,228 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileOpts configures [CreateFile].
+type FileOpts struct {
+	// EstimatedSize, when positive, is the expected size of the file in
+	// bytes. The file is extended to this size upfront, so that the file
+	// system can allocate it contiguously, and truncated to the actual size
+	// on Close.
+	EstimatedSize int64
+	// BufferSize is the size of the chunks written to the file
+	// (1 MiB when 0).
+	BufferSize int
+	// Atomic writes to a temporary file of the same directory, renamed to
+	// the file on a successful [SafeFileWriter.Close], so that partial
+	// exports are never visible to the readers of the file. An existing
+	// file is left unchanged until then.
+	Atomic bool
+	// Perm is the permissions of the file created with Atomic (0644 when
+	// 0). Otherwise the file is created with 0666, before umask.
+	Perm fs.FileMode
+}
+
+// A SafeFileWriter is a [SafeWriter] writing to a file created by
+// [CreateFile]. [SafeFileWriter.Close] must be called once all data has been
+// written.
+type SafeFileWriter struct {
+	*SafeWriter
+
+	f       *os.File
+	written countingWriter
+	size    int64
+	name    string      // Name of the file, when f is a temporary file
+	perm    fs.FileMode // Permissions of the file, when f is a temporary file
+}
+
+// CreateFile creates the named file, truncating it if it exists, and returns
+// a SafeFileWriter writing to it. It is meant for large exports to local
+// disks: writes are made in large chunks, and the file can be preallocated.
+func CreateFile(name string, opts SafetyOpts, fileOpts FileOpts) (*SafeFileWriter, error) {
+	fw := &SafeFileWriter{size: fileOpts.EstimatedSize}
+
+	var err error
+	if fileOpts.Atomic {
+		fw.f, err = os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+		fw.name, fw.perm = name, fileOpts.Perm
+		if fw.perm == 0 {
+			fw.perm = 0o644
+		}
+	} else {
+		fw.f, err = os.Create(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fileOpts.EstimatedSize > 0 {
+		if err := fw.f.Truncate(fileOpts.EstimatedSize); err != nil {
+			fw.discard()
+			return nil, err
+		}
+	}
+
+	fw.written.w = fw.f
+	fw.SafeWriter = NewSafeWriter(&fw.written, opts)
+	fw.FlushThreshold = fileOpts.BufferSize
+	if fw.FlushThreshold <= 0 {
+		fw.FlushThreshold = 1 << 20
+	}
+	return fw, nil
+}
+
+// Close closes the writer, writing its trailer if any, truncates the file to
+// the size of the data written if it was preallocated, and closes it. It
+// returns the first error.
+//
+// With [FileOpts.Atomic], the temporary file is synced to disk and renamed to
+// the file. It is removed instead if an error occurred.
+func (fw *SafeFileWriter) Close() error {
+	err := fw.SafeWriter.Close()
+
+	// The file is truncated even if an error occurred, so that a
+	// preallocated file never ends with padding.
+	if fw.size > 0 {
+		if terr := fw.f.Truncate(fw.written.n); err == nil {
+			err = terr
+		}
+	}
+	if fw.name == "" {
+		if cerr := fw.f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+
+	if err == nil {
+		err = fw.f.Chmod(fw.perm)
+	}
+	if err == nil {
+		err = fw.f.Sync()
+	}
+	if cerr := fw.f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(fw.f.Name(), fw.name)
+	}
+	if err != nil {
+		_ = os.Remove(fw.f.Name())
+	}
+	return err
+}
+
+// Discard closes the file without writing the buffered data. With
+// [FileOpts.Atomic], the temporary file is removed, leaving the file
+// unchanged. Otherwise the file is left as written so far.
+func (fw *SafeFileWriter) Discard() {
+	if fw.async != nil {
+		// Wait for the pending write, which uses the file.
+		_ = fw.async.wait()
+	}
+	fw.discard()
+}
+
+// discard closes the file and removes it if it is a temporary file.
+func (fw *SafeFileWriter) discard() {
+	_ = fw.f.Close()
+	if fw.name != "" {
+		_ = os.Remove(fw.f.Name())
+	}
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WriteFile writes records to the named file, sanitized according to opts,
+// creating it with permissions perm, or replacing it. The file is written
+// with [FileOpts.Atomic]: readers of the file never see a partial export,
+// even after a crash.
+func WriteFile(name string, records [][]string, opts SafetyOpts, perm fs.FileMode) error {
+	fw, err := CreateFile(name, opts, FileOpts{Atomic: true, Perm: perm})
+	if err != nil {
+		return err
+	}
+
+	if err := fw.WriteAll(records); err != nil {
+		fw.Discard()
+		return err
+	}
+	return fw.Close()
+}
+
+// AppendFile appends records to the named file, sanitized according to
+// opts, creating it with permissions perm if it does not exist. The records
+// are encoded with the line terminator of the first line of the file, then
+// appended with a single write, synced to disk: a record rejected by the
+// writer leaves the file unchanged. A missing line terminator at the end of
+// the file is added.
+func AppendFile(name string, records [][]string, opts SafetyOpts, perm fs.FileMode) error {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+
+	err = appendRecords(f, records, opts)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// appendRecords encodes records with the line terminator used by f, and
+// appends them to f.
+func appendRecords(f *os.File, records [][]string, opts SafetyOpts) error {
+	crlf, err := usesCRLF(f)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w := NewSafeWriter(&buf, opts)
+	w.UseCRLF = crlf
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+	return appendTerminated(f, buf.Bytes(), w.lineEnd())
+}
+
+// appendTerminated appends data to f, after the line terminator end if f is
+// not empty and does not end with one.
+func appendTerminated(f *os.File, data []byte, end string) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > 0 {
+		terminated, err := endsWithNewline(f)
+		if err != nil {
+			return err
+		}
+		if !terminated {
+			data = append([]byte(end), data...)
+		}
+	}
+
+	_, err = f.Write(data)
+	return err
+}
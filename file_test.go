@@ -0,0 +1,157 @@
+package csv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFile(t *testing.T) {
+	is := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+
+	w, err := CreateFile(path, EscapeAll, FileOpts{EstimatedSize: 1 << 16, BufferSize: 64})
+	is.NoError(err)
+
+	info, err := os.Stat(path)
+	is.NoError(err)
+	is.EqualValues(1<<16, info.Size())
+
+	for i := 0; i < 100; i++ {
+		is.NoError(w.Write([]string{"abc", "=def"}))
+	}
+	is.NoError(w.Close())
+
+	b, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Equal(strings.Repeat("abc,\" =def\"\n", 100), string(b))
+
+	w, err = CreateFile(path, EscapeAll, FileOpts{})
+	is.NoError(err)
+	is.NoError(w.WriteAll([][]string{{"a"}}))
+	is.NoError(w.Close())
+
+	b, err = os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("a\n", string(b))
+
+	w, err = CreateFile(path, EscapeAll, FileOpts{EstimatedSize: 1 << 10})
+	is.NoError(err)
+	w.MaxErrors = 1
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.NoError(w.Write([]string{"a"}))
+	w.Flush()
+	is.Error(w.Write([]string{""}))
+	is.ErrorIs(w.Write([]string{""}), ErrTooManyErrors)
+	is.ErrorIs(w.Close(), ErrTooManyErrors)
+
+	b, err = os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("a\n", string(b))
+
+	_, err = CreateFile(filepath.Join(t.TempDir(), "missing", "export.csv"), EscapeAll, FileOpts{})
+	is.Error(err)
+}
+
+func TestWriteFile(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.csv")
+
+	is.NoError(WriteFile(path, [][]string{{"id", "name"}, {"1", "=a"}}, EscapeAll, 0o600))
+	is.NoError(WriteFile(path, [][]string{{"id"}, {"2"}}, EscapeAll, 0o600))
+
+	b, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("id\n2\n", string(b))
+
+	info, err := os.Stat(path)
+	is.NoError(err)
+	is.Equal(os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	is.NoError(err)
+	is.Len(entries, 1)
+
+	is.Error(WriteFile(filepath.Join(dir, "missing", "export.csv"), nil, EscapeAll, 0o600))
+}
+
+func TestAppendFile(t *testing.T) {
+	is := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+
+	is.NoError(AppendFile(path, [][]string{{"id", "name"}, {"1", "=a"}}, EscapeAll, 0o600))
+
+	b, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("id,name\n1,\" =a\"\n", string(b))
+
+	// The last record is not terminated.
+	is.NoError(os.WriteFile(path, b[:len(b)-1], 0o600))
+	is.NoError(AppendFile(path, [][]string{{"2", "b"}}, EscapeAll, 0o600))
+	is.NoError(AppendFile(path, [][]string{{"3", "c"}}, EscapeAll, 0o600))
+
+	b, err = os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("id,name\n1,\" =a\"\n2,b\n3,c\n", string(b))
+
+	// The file uses CRLF line endings.
+	is.NoError(os.WriteFile(path, []byte("id,name\r\n1,a"), 0o600))
+	is.NoError(AppendFile(path, [][]string{{"2", "b\nc"}, {"3", "=d"}}, EscapeAll, 0o600))
+
+	b, err = os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("id,name\r\n1,a\r\n2,\"b\r\nc\"\r\n3,\" =d\"\r\n", string(b))
+
+	is.Error(AppendFile(filepath.Join(path, "export.csv"), nil, EscapeAll, 0o600))
+}
+
+func TestCreateFileAtomic(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.csv")
+	is.NoError(os.WriteFile(path, []byte("old\n"), 0o600))
+
+	w, err := CreateFile(path, EscapeAll, FileOpts{Atomic: true, EstimatedSize: 1 << 10, BufferSize: 4})
+	is.NoError(err)
+	is.NoError(w.Write([]string{"a", "=b"}))
+	is.NoError(w.Write([]string{"c"}))
+
+	b, err := os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("old\n", string(b))
+
+	entries, err := os.ReadDir(dir)
+	is.NoError(err)
+	is.Len(entries, 2)
+
+	is.NoError(w.Close())
+
+	b, err = os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("a,\" =b\"\nc\n", string(b))
+
+	info, err := os.Stat(path)
+	is.NoError(err)
+	is.Equal(os.FileMode(0o644), info.Mode().Perm())
+
+	w, err = CreateFile(path, EscapeAll, FileOpts{Atomic: true})
+	is.NoError(err)
+	is.NoError(w.Write([]string{"d"}))
+	w.Discard()
+
+	b, err = os.ReadFile(path)
+	is.NoError(err)
+	is.Equal("a,\" =b\"\nc\n", string(b))
+
+	entries, err = os.ReadDir(dir)
+	is.NoError(err)
+	is.Len(entries, 1)
+}
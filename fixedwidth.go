@@ -0,0 +1,226 @@
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Alignment controls on which side of a padded field the padding goes.
+type Alignment int
+
+const (
+	// AlignLeft writes the value first and pads on the right.
+	AlignLeft Alignment = iota
+	// AlignRight pads on the left and writes the value last.
+	AlignRight
+)
+
+// Overflow controls what happens to a value wider than its column.
+type Overflow int
+
+const (
+	// OverflowError rejects the record with [ErrFieldTooWide].
+	OverflowError Overflow = iota
+	// OverflowTruncate cuts the value to the column width.
+	OverflowTruncate
+)
+
+// FixedWidthColumn describes a single column of a [FixedWidthWriter].
+type FixedWidthColumn struct {
+	Width    int       // Width of the column, in runes
+	Align    Alignment // Side of the value where padding is added
+	Overflow Overflow  // Policy for values wider than Width
+}
+
+var (
+	// ErrFieldCount is returned when a record does not have one field per column.
+	ErrFieldCount = errors.New("csv: wrong number of fields")
+	// ErrFieldTooWide is returned when a value does not fit its column and
+	// the column uses [OverflowError].
+	ErrFieldTooWide = errors.New("csv: field exceeds column width")
+
+	errFixedWidthNewline = errors.New("csv: fixed-width field contains a line break")
+	errInvalidPadding    = errors.New("csv: invalid padding character")
+	errInvalidWidth      = errors.New("csv: invalid column width")
+)
+
+// A FixedWidthWriter writes records as padded, fixed-width columns.
+//
+// The same [SafetyOpts] as [SafeWriter] apply to every field, except
+// ForceDoubleQuotes: fixed-width output is never quoted. Since the prefix
+// added by escaping is part of the value, it counts toward the column width.
+//
+// [FixedWidthWriter.Columns] applies the Transformers, Required, Type and
+// Allowed options of [ColumnOpts] before escaping, as [SafeWriter] does;
+// their other options are ignored. Errors of a field are returned as a
+// [ColumnError].
+//
+// [FixedWidthWriter.Padding] is the character used to fill columns.
+//
+// If [FixedWidthWriter.UseCRLF] is true,
+// the FixedWidthWriter ends each output line with \r\n instead of \n.
+//
+// As with [SafeWriter], writes are buffered and [FixedWidthWriter.Flush]
+// must be called once all data has been written.
+type FixedWidthWriter struct {
+	Padding rune         // Fill character (set to ' ' by NewFixedWidthWriter)
+	UseCRLF bool         // True to use \r\n as the line terminator
+	Columns []ColumnOpts // Per-column transformers and validation, by field position
+	w       *bufio.Writer
+	columns []FixedWidthColumn
+	opts    SafetyOpts
+	rows    int64 // Number of records written
+}
+
+// NewFixedWidthWriter returns a new FixedWidthWriter that writes to w.
+func NewFixedWidthWriter(w io.Writer, columns []FixedWidthColumn, opts SafetyOpts) *FixedWidthWriter {
+	return &FixedWidthWriter{
+		Padding: ' ',
+		w:       bufio.NewWriter(w),
+		columns: columns,
+		opts:    opts,
+	}
+}
+
+// Write writes a single record to w, padding or truncating each field to
+// the width of its column. The record must have exactly one field per column.
+// Writes are buffered, so [FixedWidthWriter.Flush] must eventually be called.
+func (w *FixedWidthWriter) Write(record []string) error {
+	if w.Padding == '\r' || w.Padding == '\n' || !utf8.ValidRune(w.Padding) {
+		return errInvalidPadding
+	}
+
+	if len(record) != len(w.columns) {
+		return ErrFieldCount
+	}
+
+	// Fields are validated before anything is written, so that a
+	// rejected record never leaves a partial line behind.
+	fields := make([]string, len(record))
+	for n, field := range record {
+		field, err := w.prepareField(n, field)
+		if err != nil {
+			return w.columnError(n, field, err)
+		}
+		fields[n] = field
+	}
+
+	for n, field := range fields {
+		col := w.columns[n]
+		padding := strings.Repeat(string(w.Padding), col.Width-utf8.RuneCountInString(field))
+
+		if col.Align == AlignRight {
+			field = padding + field
+		} else {
+			field = field + padding
+		}
+
+		if _, err := w.w.WriteString(field); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if w.UseCRLF {
+		_, err = w.w.WriteString("\r\n")
+	} else {
+		err = w.w.WriteByte('\n')
+	}
+	if err == nil {
+		w.rows++
+	}
+	return err
+}
+
+// prepareField transforms, validates, escapes and fits the field at
+// position n. On failure, it returns the value that was rejected.
+func (w *FixedWidthWriter) prepareField(n int, field string) (string, error) {
+	var col ColumnOpts
+	if n < len(w.Columns) {
+		col = w.Columns[n]
+	}
+
+	transformed, err := col.transform(field)
+	if err != nil {
+		return field, err
+	}
+	field = transformed
+	if strings.ContainsAny(field, "\r\n") {
+		return field, errFixedWidthNewline
+	}
+	if err := col.validate(field); err != nil {
+		return field, err
+	}
+
+	escaped, err := w.opts.escape(field, n)
+	if err != nil {
+		return field, err
+	}
+	fitted, err := w.columns[n].fit(escaped)
+	if err != nil {
+		return escaped, err
+	}
+	return fitted, nil
+}
+
+// columnError returns a [ColumnError] for the failure of the field at
+// position n of the current record.
+func (w *FixedWidthWriter) columnError(n int, value string, err error) error {
+	var name string
+	if n < len(w.Columns) {
+		name = w.Columns[n].Name
+	}
+	return &ColumnError{Row: w.rows, Column: n, Name: name, Value: value, Err: err}
+}
+
+// fit applies the overflow policy of the column to field.
+func (col FixedWidthColumn) fit(field string) (string, error) {
+	if col.Width < 0 {
+		return "", errInvalidWidth
+	}
+
+	if utf8.RuneCountInString(field) <= col.Width {
+		return field, nil
+	}
+
+	if col.Overflow != OverflowTruncate {
+		return "", ErrFieldTooWide
+	}
+
+	runes := 0
+	for i := range field {
+		if runes == col.Width {
+			return field[:i], nil
+		}
+		runes++
+	}
+	return field, nil
+}
+
+// Flush writes any buffered data to the underlying [io.Writer].
+// To check if an error occurred during Flush, call [FixedWidthWriter.Error].
+func (w *FixedWidthWriter) Flush() {
+	_ = w.w.Flush()
+}
+
+// Error reports any error that has occurred during
+// a previous [FixedWidthWriter.Write] or [FixedWidthWriter.Flush].
+func (w *FixedWidthWriter) Error() error {
+	_, err := w.w.Write(nil)
+	return err
+}
+
+// WriteAll writes multiple records to w using [FixedWidthWriter.Write] and
+// then calls [FixedWidthWriter.Flush], returning any error from the Flush.
+func (w *FixedWidthWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		err := w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
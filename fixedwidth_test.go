@@ -0,0 +1,87 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedWidthWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	columns := []FixedWidthColumn{
+		{Width: 6},
+		{Width: 5, Align: AlignRight},
+		{Width: 4, Overflow: OverflowTruncate},
+	}
+	w := NewFixedWidthWriter(&buff, columns, EscapeAll)
+	is.NoError(w.WriteAll([][]string{
+		{"userId", "42", "comment"},
+		{"=A1", "-1", "é"},
+	}))
+	is.Equal("userId   42comm\n =A1     -1é   \n", buff.String())
+
+	buff.Reset()
+	w = NewFixedWidthWriter(&buff, columns, SafetyOpts{})
+	w.Padding = '0'
+	w.UseCRLF = true
+	is.NoError(w.WriteAll([][]string{{"a", "7", "b"}}))
+	is.Equal("a0000000007b000\r\n", buff.String())
+}
+
+func TestFixedWidthWriterErrors(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewFixedWidthWriter(&buff, []FixedWidthColumn{{Width: 3}, {Width: 3}}, SafetyOpts{})
+	is.ErrorIs(w.Write([]string{"abc"}), ErrFieldCount)
+	is.ErrorIs(w.Write([]string{"abc", "abcd"}), ErrFieldTooWide)
+	is.ErrorIs(w.Write([]string{"a\nb", "c"}), errFixedWidthNewline)
+
+	w.Padding = '\n'
+	is.ErrorIs(w.Write([]string{"a", "b"}), errInvalidPadding)
+
+	w = NewFixedWidthWriter(&buff, []FixedWidthColumn{{Width: -1}}, SafetyOpts{})
+	is.ErrorIs(w.Write([]string{""}), errInvalidWidth)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Empty(buff.String())
+}
+
+func TestFixedWidthWriterColumns(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewFixedWidthWriter(&buff, []FixedWidthColumn{{Width: 3}, {Width: 8}}, EscapeAll)
+	w.Columns = []ColumnOpts{
+		{Name: "id", Required: true, Type: TypeInteger},
+		{Name: "card", Transformers: []Transformer{Mask('*', 4)}},
+	}
+	is.NoError(w.Write([]string{"1", "12345678"}))
+	is.NoError(w.Write([]string{"2", "=123"}))
+
+	var colErr *ColumnError
+	err := w.Write([]string{"x", "1234"})
+	is.ErrorIs(err, ErrTypeMismatch)
+	is.ErrorAs(err, &colErr)
+	is.Equal(&ColumnError{Row: 2, Column: 0, Name: "id", Value: "x", Err: ErrTypeMismatch}, colErr)
+
+	err = w.Write([]string{"3", "123456789"})
+	is.ErrorIs(err, ErrFieldTooWide)
+	is.ErrorAs(err, &colErr)
+	is.Equal(1, colErr.Column)
+	is.Equal("*****6789", colErr.Value)
+
+	is.ErrorAs(w.Write([]string{"", "1"}), &colErr)
+	is.ErrorIs(colErr, ErrRequiredField)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("1  ****5678\n2   =123   \n", buff.String())
+}
@@ -0,0 +1,32 @@
+package csv
+
+// FlattenOpts configures how [SafeWriter.WriteStruct] and
+// [FlattenOpts.StructHeader] write nested structs, so that rich domain
+// models can be exported without intermediate types.
+//
+// Struct fields, and pointers to structs, are flattened into one column per
+// field, named after the field joined to the names of its parents. Structs
+// implementing [Cell], [encoding.TextMarshaler] or [fmt.Stringer], such as
+// [time.Time], are written as a single field. Fields of nil pointers are
+// written as empty fields.
+type FlattenOpts struct {
+	// MaxDepth is the number of levels of nested structs flattened. Deeper
+	// structs are written as single fields, and 0 disables flattening.
+	// Self-referential structs, such as linked list nodes, are flattened up
+	// to MaxDepth. A negative MaxDepth flattens every level, and reports
+	// self-referential structs as errors.
+	MaxDepth int
+	// Separator joins the names of nested fields ("." when empty).
+	Separator string
+	// PrefixEmbedded prefixes the fields of embedded structs with the name
+	// of the struct, like the fields of other nested structs. Otherwise
+	// they are promoted without prefix, like encoding/json does.
+	PrefixEmbedded bool
+}
+
+func (opts FlattenOpts) separator() string {
+	if opts.Separator == "" {
+		return "."
+	}
+	return opts.Separator
+}
@@ -0,0 +1,131 @@
+package csv
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var errNotFinite = errors.New("csv: number is not finite")
+
+// BoolFormat describes how booleans are rendered.
+type BoolFormat struct {
+	True  string
+	False string
+}
+
+// Common boolean renderings.
+var (
+	BoolTrueFalse = BoolFormat{True: "true", False: "false"}
+	BoolOneZero   = BoolFormat{True: "1", False: "0"}
+	BoolYesNo     = BoolFormat{True: "yes", False: "no"}
+	BoolYN        = BoolFormat{True: "Y", False: "N"}
+)
+
+// render returns the text of value.
+func (format BoolFormat) render(value bool) string {
+	if value {
+		return format.True
+	}
+	return format.False
+}
+
+// CurrencyFormat describes how [FormatCurrency] renders amounts.
+type CurrencyFormat struct {
+	Symbol      string // Currency symbol, such as "$" or " €"
+	SymbolAfter bool   // True to write the symbol after the amount
+	Thousands   rune   // Thousands separator (none when 0)
+	Decimal     rune   // Decimal separator ('.' when 0)
+	Decimals    int    // Number of decimal digits
+}
+
+// FormatCurrency returns a [Transformer] rendering numeric fields as amounts,
+// such as 1234.5 as $1,234.50. Empty fields are left untouched, and
+// infinities and NaN are rejected.
+//
+// Negative amounts keep their leading minus sign, so they are escaped when
+// [SafetyOpts.EscapeCharMinus] is set, even for a [NumberCell].
+func FormatCurrency(format CurrencyFormat) Transformer {
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+
+		value, err := parseFinite(field)
+		if err != nil {
+			return "", err
+		}
+
+		sign := ""
+		if value < 0 {
+			sign = "-"
+			value = -value
+		}
+
+		amount := formatDecimal(value, format.Decimals, format.Thousands, format.Decimal)
+		if format.SymbolAfter {
+			return sign + amount + format.Symbol, nil
+		}
+		return sign + format.Symbol + amount, nil
+	}
+}
+
+// FormatPercent returns a [Transformer] rendering ratios as percentages, such
+// as 0.125 as 12.5% with one decimal. Empty fields are left untouched, and
+// infinities and NaN are rejected.
+func FormatPercent(decimals int) Transformer {
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+
+		value, err := parseFinite(field)
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.FormatFloat(value*100, 'f', decimals, 64) + "%", nil
+	}
+}
+
+// parseFinite parses field as a number, rejecting infinities and NaN.
+func parseFinite(field string) (float64, error) {
+	value, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, errNotFinite
+	}
+	return value, nil
+}
+
+// formatDecimal formats a non-negative value with the given separators.
+func formatDecimal(value float64, decimals int, thousands rune, decimal rune) string {
+	if decimal == 0 {
+		decimal = '.'
+	}
+
+	digits := strconv.FormatFloat(value, 'f', decimals, 64)
+	integer, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		integer, fraction = digits[:i], digits[i+1:]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(integer); i++ {
+		if thousands != 0 && i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteRune(thousands)
+		}
+		b.WriteByte(integer[i])
+	}
+
+	if fraction != "" {
+		var buf [utf8.UTFMax]byte
+		b.Write(buf[:utf8.EncodeRune(buf[:], decimal)])
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCurrency(t *testing.T) {
+	is := assert.New(t)
+
+	usd := FormatCurrency(CurrencyFormat{Symbol: "$", Thousands: ',', Decimals: 2})
+	eur := FormatCurrency(CurrencyFormat{Symbol: " €", SymbolAfter: true, Thousands: ' ', Decimal: ',', Decimals: 2})
+	yen := FormatCurrency(CurrencyFormat{Symbol: "¥"})
+
+	for input, expected := range map[string]string{
+		"1234.5":     "$1,234.50",
+		"-1234567.1": "-$1,234,567.10",
+		"0":          "$0.00",
+		"999":        "$999.00",
+		"":           "",
+	} {
+		out, err := usd(input)
+		is.NoError(err)
+		is.Equal(expected, out, input)
+	}
+
+	out, err := eur("1234567.891")
+	is.NoError(err)
+	is.Equal("1 234 567,89 €", out)
+
+	out, err = yen("1234.4")
+	is.NoError(err)
+	is.Equal("¥1234", out)
+
+	_, err = usd("abc")
+	is.Error(err)
+
+	for _, input := range []string{"Inf", "-Inf", "+inf", "NaN"} {
+		_, err = usd(input)
+		is.ErrorIs(err, errNotFinite, input)
+	}
+}
+
+func TestFormatPercent(t *testing.T) {
+	is := assert.New(t)
+
+	percent := FormatPercent(1)
+
+	out, err := percent("0.125")
+	is.NoError(err)
+	is.Equal("12.5%", out)
+
+	out, err = percent("")
+	is.NoError(err)
+	is.Equal("", out)
+
+	_, err = percent("12%")
+	is.Error(err)
+
+	_, err = percent("-Inf")
+	is.ErrorIs(err, errNotFinite)
+}
+
+func TestColumnTransformers(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{
+		{},
+		{Transformers: []Transformer{FormatCurrency(CurrencyFormat{Symbol: "$", Thousands: ',', Decimals: 2})}},
+		{Transformers: []Transformer{FormatPercent(0)}},
+	}
+	is.NoError(w.Write([]string{"a", "1234.5", "0.5"}))
+	is.NoError(w.WriteCells([]Cell{StringCell("b"), NumberCell(-3), NumberCell(0.25)}))
+
+	err := w.Write([]string{"c", "oops", "0.5"})
+	var colErr *ColumnError
+	is.ErrorAs(err, &colErr)
+	is.Equal(1, colErr.Column)
+	is.Equal("oops", colErr.Value)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("a,\"$1,234.50\",50%\nb,\" -$3.00\",25%\n", buff.String())
+	is.Equal(Stats{Records: 2, SanitizedFields: 1, RejectedRecords: 1}, w.Stats())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	trim := func(field string) (string, error) { return strings.TrimSpace(field), nil }
+	w.Columns = []ColumnOpts{{Transformers: []Transformer{trim}}, {Transformers: []Transformer{trim}}}
+	is.NoError(w.WriteCells([]Cell{Formula("=SUM(A1:A2)"), Formula(" =1+1")}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("=SUM(A1:A2),\" =1+1\"\n", buff.String())
+}
+
+func TestBoolFormat(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, FullSafety)
+	is.NoError(w.WriteCells([]Cell{BoolCell(true), BoolCell(false)}))
+
+	w.BoolFormat = BoolYesNo
+	w.Columns = []ColumnOpts{{}, {BoolFormat: BoolOneZero}, {BoolFormat: BoolYN}}
+	is.NoError(w.WriteCells([]Cell{BoolCell(true), BoolCell(false), WithQuote(BoolCell(true), QuoteMinimal)}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"true\",\"false\"\n\"yes\",\"0\",Y\n", buff.String())
+}
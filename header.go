@@ -0,0 +1,384 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrUnknownColumn is returned when a column is selected by a name
+	// missing from the header.
+	ErrUnknownColumn = errors.New("csv: unknown column")
+	// ErrDuplicateColumn is returned when a header holds the same name twice
+	// and [SafeWriter.RenameDuplicates] is not set.
+	ErrDuplicateColumn = errors.New("csv: duplicate column")
+
+	errHeaderAfterRecords = errors.New("csv: header must be the first record")
+	errMissingHeader      = errors.New("csv: a header is required to write named fields")
+)
+
+// HeaderCase normalizes the names of a header.
+type HeaderCase int
+
+const (
+	// HeaderAsIs keeps names unchanged.
+	HeaderAsIs HeaderCase = iota
+	// HeaderTrim removes leading and trailing spaces.
+	HeaderTrim
+	// HeaderLowerSnake writes names as lower_snake_case: "User ID" and
+	// "userId" become "user_id".
+	HeaderLowerSnake
+	// HeaderTitle writes names as Title Case: "user_id" becomes "User Id".
+	HeaderTitle
+)
+
+// normalize applies the case to name.
+func (c HeaderCase) normalize(name string) string {
+	switch c {
+	case HeaderTrim:
+		return strings.TrimSpace(name)
+	case HeaderLowerSnake:
+		words := headerWords(name)
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+		return strings.Join(words, "_")
+	case HeaderTitle:
+		words := headerWords(name)
+		for i, word := range words {
+			runes := []rune(strings.ToLower(word))
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		return strings.Join(words, " ")
+	default:
+		return name
+	}
+}
+
+// headerWords splits name into words, on punctuation, spaces and case
+// changes: "HTTPServer_id" is made of "HTTP", "Server" and "id".
+func headerWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// SelectColumns restricts the output to the named columns, in the given
+// order. It must be called before [SafeWriter.WriteHeader], which then
+// receives the header of the input records: [SafeWriter.Write] and
+// [SafeWriter.WriteCells] pick their fields by position in that header, while
+// [SafeWriter.WriteMap] and [SafeWriter.WriteStruct] pick them by name.
+//
+// [SafeWriter.Columns] apply to the output, after projection.
+func (w *SafeWriter) SelectColumns(names ...string) {
+	w.selected = append([]string{}, names...)
+}
+
+// RenameColumns sets the titles written in the header in place of the given
+// column names. It must be called before [SafeWriter.WriteHeader].
+//
+// Renaming only affects the header row: [SafeWriter.SelectColumns],
+// [SafeWriter.WriteMap], [SafeWriter.WriteStruct] and errors keep using the
+// original names.
+func (w *SafeWriter) RenameColumns(titles map[string]string) {
+	w.titles = make(map[string]string, len(titles))
+	for name, title := range titles {
+		w.titles[name] = title
+	}
+}
+
+// title returns the title given to the column name by
+// [SafeWriter.RenameColumns].
+func (w *SafeWriter) title(name string) (string, bool) {
+	for original, title := range w.titles {
+		if w.HeaderCase.normalize(original) == name {
+			return title, true
+		}
+	}
+	return "", false
+}
+
+// WriteHeader writes the header record and declares the names of the
+// columns, which are then used to locate errors and to write named fields.
+//
+// Names are first normalized according to [SafeWriter.HeaderCase], as are
+// the names given to [SafeWriter.SelectColumns], [SafeWriter.RenameColumns],
+// [SafeWriter.WriteMap] and [SafeWriter.WriteStruct] when matched against
+// the header.
+//
+// The header is sanitized and padded like any record, but transformers and
+// validation rules of the columns do not apply to it. It must be written
+// before any other record.
+func (w *SafeWriter) WriteHeader(header []string) error {
+	if !validDelim(w.Comma) {
+		return errInvalidDelim
+	}
+
+	if w.header != nil || w.stats.Records > 0 {
+		return errHeaderAfterRecords
+	}
+
+	if w.HeaderCase != HeaderAsIs {
+		normalized := make([]string, len(header))
+		for i, name := range header {
+			normalized[i] = w.HeaderCase.normalize(name)
+		}
+		header = normalized
+	}
+
+	if w.RenameDuplicates {
+		header = renameDuplicates(header)
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := positions[name]; ok {
+			return fmt.Errorf("%w: %q", ErrDuplicateColumn, name)
+		}
+		positions[name] = i
+	}
+
+	output := header
+	var projection []int
+	if w.selected != nil {
+		output = make([]string, len(w.selected))
+		projection = make([]int, len(w.selected))
+		for i, name := range w.selected {
+			name = w.HeaderCase.normalize(name)
+			output[i] = name
+			position, ok := positions[name]
+			if !ok {
+				return fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+			}
+			projection[i] = position
+		}
+	}
+
+	for _, col := range w.computed {
+		output = append(output[:len(output):len(output)], col.name)
+	}
+
+	w.fields = w.fields[:0]
+	for n, name := range output {
+		if title, ok := w.title(name); ok {
+			name = title
+		}
+
+		prepared := preparedField{value: name, quote: w.quoteMode(QuoteAuto)}
+		escaped, err := w.opts.escape(name, n)
+		if err != nil {
+			return w.columnError(n, name, err)
+		}
+		if escaped != name {
+			prepared.value = escaped
+			prepared.sanitized = true
+		}
+		prepared.value = w.column(n).pad(prepared.value)
+		w.fields = append(w.fields, prepared)
+	}
+
+	if err := w.writeFields(); err != nil {
+		return err
+	}
+
+	w.input = append([]string{}, header...)
+	w.header = append([]string{}, output...)
+	w.projection = projection
+	w.headers = 1
+	return w.quarantineHeader()
+}
+
+// renameDuplicates renames the repeated names of header as name_2, name_3,
+// and so on, skipping names already used by other columns.
+func renameDuplicates(header []string) []string {
+	used := make(map[string]bool, len(header))
+	for _, name := range header {
+		used[name] = true
+	}
+
+	renamed := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		if !seen[name] {
+			seen[name] = true
+			renamed[i] = name
+			continue
+		}
+
+		for k := 2; ; k++ {
+			candidate := name + "_" + strconv.Itoa(k)
+			if !used[candidate] {
+				used[candidate] = true
+				seen[candidate] = true
+				renamed[i] = candidate
+				break
+			}
+		}
+	}
+	return renamed
+}
+
+// columnName returns the name of the column at position n, if known.
+func (w *SafeWriter) columnName(n int) string {
+	if name := w.column(n).Name; name != "" {
+		return name
+	}
+	if n < len(w.header) {
+		return w.header[n]
+	}
+	return ""
+}
+
+// project returns the fields of record selected by [SafeWriter.SelectColumns].
+func (w *SafeWriter) project(record []string) []string {
+	if w.projection == nil {
+		return record
+	}
+
+	w.projected = w.projected[:0]
+	for _, position := range w.projection {
+		field := ""
+		if position < len(record) {
+			field = record[position]
+		}
+		w.projected = append(w.projected, field)
+	}
+	return w.projected
+}
+
+// projectCells is the [Cell] counterpart of [SafeWriter.project].
+func (w *SafeWriter) projectCells(cells []Cell) []Cell {
+	if w.projection == nil {
+		return cells
+	}
+
+	projected := make([]Cell, len(w.projection))
+	for i, position := range w.projection {
+		if position < len(cells) {
+			projected[i] = cells[position]
+		} else {
+			projected[i] = StringCell("")
+		}
+	}
+	return projected
+}
+
+// WriteMap writes a single CSV record whose fields are given by column name.
+// A header must have been written first, unless [SafeWriter.AutoHeader] is
+// set; names missing from record are written with the default value of their
+// column, and names missing from the header are ignored.
+func (w *SafeWriter) WriteMap(record map[string]string) error {
+	if w.input == nil {
+		if !w.AutoHeader {
+			return errMissingHeader
+		}
+
+		header := make([]string, 0, len(record))
+		for name := range record {
+			header = append(header, name)
+		}
+		sort.Strings(header)
+		if err := w.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+
+	if w.HeaderCase != HeaderAsIs {
+		normalized := make(map[string]string, len(record))
+		for name, value := range record {
+			normalized[w.HeaderCase.normalize(name)] = value
+		}
+		record = normalized
+	}
+
+	for name := range record {
+		if err := w.checkInputName(name); err != nil {
+			return err
+		}
+	}
+
+	cells := make([]Cell, len(w.input))
+	for i, name := range w.input {
+		if value, ok := record[name]; ok {
+			cells[i] = StringCell(value)
+		} else {
+			cells[i] = w.missingField(i)
+		}
+	}
+	return w.WriteCells(cells)
+}
+
+// checkInputName reports names missing from the header of the input records
+// when [SafeWriter.AutoHeader] is set.
+func (w *SafeWriter) checkInputName(name string) error {
+	if !w.AutoHeader {
+		return nil
+	}
+
+	for _, input := range w.input {
+		if input == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+}
+
+// missingField returns the cell written for the input column at position
+// when a named record has no value for it.
+func (w *SafeWriter) missingField(position int) Cell {
+	return StringCell(w.inputColumn(position).Default)
+}
+
+// nullField returns the cell written for a null value at position of the
+// input records.
+func (w *SafeWriter) nullField(position int) Cell {
+	return StringCell(w.inputColumn(position).null())
+}
+
+// inputColumn returns the options of the column written for position of
+// the input records.
+func (w *SafeWriter) inputColumn(position int) ColumnOpts {
+	if w.projection == nil {
+		return w.column(position)
+	}
+
+	for n, p := range w.projection {
+		if p == position {
+			return w.column(n)
+		}
+	}
+	return ColumnOpts{}
+}
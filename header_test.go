@@ -0,0 +1,157 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHeader(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{
+		{Required: true},
+		{Name: "Mail", Required: true, Width: 6},
+		{Allowed: []string{"x"}},
+	}
+	is.NoError(w.WriteHeader([]string{"id", "email", "=cmd"}))
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errHeaderAfterRecords)
+	is.NoError(w.Write([]string{"1", "a@b.c", "x"}))
+
+	err := w.Write([]string{"", "a@b.c", "x"})
+	is.ErrorIs(err, ErrRequiredField)
+	is.EqualError(err, `csv: record 2, column 0 ("id"): required field is empty`)
+
+	err = w.Write([]string{"2", "", "x"})
+	is.EqualError(err, `csv: record 2, column 1 ("Mail"): required field is empty`)
+
+	err = w.Write([]string{"2", "a@b.c", "y"})
+	is.EqualError(err, `csv: record 2, column 2 ("=cmd"): value not allowed`)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,email ,\" =cmd\"\n1,a@b.c ,x\n", buff.String())
+	is.Equal(Stats{Records: 2, SanitizedFields: 1, RejectedRecords: 3}, w.Stats())
+}
+
+func TestWriteHeaderAfterRecords(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, SafetyOpts{})
+	is.NoError(w.Write([]string{"1"}))
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errHeaderAfterRecords)
+
+	w.Comma = '\n'
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errInvalidDelim)
+}
+
+func TestRenameColumns(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	titles := map[string]string{"id": "Customer ID", "email": "=E-mail", "unknown": "x"}
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.SelectColumns("email", "id")
+	w.RenameColumns(titles)
+	titles["id"] = "changed"
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.NoError(w.WriteHeader([]string{"id", "email"}))
+	is.NoError(w.WriteMap(map[string]string{"id": "1", "email": "a@b.c"}))
+
+	err := w.WriteMap(map[string]string{"id": "2"})
+	is.EqualError(err, `csv: record 2, column 0 ("email"): required field is empty`)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\" =E-mail\",Customer ID\na@b.c,1\n", buff.String())
+}
+
+func TestColumnDefault(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.SelectColumns("country", "id", "status")
+	w.Columns = []ColumnOpts{{Default: "FR"}, {}, {Default: "-"}}
+	is.NoError(w.WriteHeader([]string{"id", "status", "country", "note"}))
+	is.NoError(w.WriteMap(map[string]string{"id": "1"}))
+	is.NoError(w.WriteMap(map[string]string{"id": "2", "country": "", "status": "ok"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("country,id,status\nFR,1,\" -\"\n,2,ok\n", buff.String())
+}
+
+func TestWriteMapAutoHeader(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.AutoHeader = true
+	w.HeaderCase = HeaderLowerSnake
+	is.NoError(w.WriteMap(map[string]string{"name": "@alice", "id": "1", "userCountry": "fr"}))
+	is.NoError(w.WriteMap(map[string]string{"id": "2", "user_country": "us"}))
+	is.ErrorIs(w.WriteMap(map[string]string{"id": "3", "email": "carol@example.com"}), ErrUnknownColumn)
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errHeaderAfterRecords)
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,name,user_country\n1,\" @alice\",fr\n2,,us\n", buff.String())
+}
+
+func TestRenameDuplicates(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal([]string{"a", "b", "a_3", "a_2", "b_2", "a_4"}, renameDuplicates([]string{"a", "b", "a", "a_2", "b", "a"}))
+	is.Equal([]string{"", "_2"}, renameDuplicates([]string{"", ""}))
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.RenameDuplicates = true
+	w.SelectColumns("name_2", "name")
+	is.NoError(w.WriteHeader([]string{"name", "name"}))
+	is.NoError(w.Write([]string{"first", "second"}))
+	is.NoError(w.WriteMap(map[string]string{"name": "a", "name_2": "b"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("name_2,name\nsecond,first\nb,a\n", buff.String())
+}
+
+func TestHeaderCase(t *testing.T) {
+	is := assert.New(t)
+
+	for input, expected := range map[string][4]string{
+		" User ID ":     {" User ID ", "User ID", "user_id", "User Id"},
+		"userId":        {"userId", "userId", "user_id", "User Id"},
+		"HTTPServer_id": {"HTTPServer_id", "HTTPServer_id", "http_server_id", "Http Server Id"},
+		"e-mail":        {"e-mail", "e-mail", "e_mail", "E Mail"},
+		"address2Line":  {"address2Line", "address2Line", "address2_line", "Address2 Line"},
+		"été":           {"été", "été", "été", "Été"},
+		" - ":           {" - ", "-", "", ""},
+	} {
+		for c, name := range expected {
+			is.Equal(name, HeaderCase(c).normalize(input), input)
+		}
+	}
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.HeaderCase = HeaderLowerSnake
+	w.SelectColumns("UserID", "Email")
+	w.RenameColumns(map[string]string{"email": "Mail"})
+	is.NoError(w.WriteHeader([]string{"User ID", "Email", "Name"}))
+	is.NoError(w.WriteMap(map[string]string{"userId": "1", "email": "a@b.c"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("user_id,Mail\n1,a@b.c\n", buff.String())
+}
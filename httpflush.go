@@ -0,0 +1,43 @@
+package csv
+
+import "time"
+
+// flusher is implemented by writers that buffer data themselves, such as
+// the [net/http.ResponseWriter] of streamed responses, see
+// [net/http.Flusher].
+type flusher interface {
+	Flush()
+}
+
+// autoFlush flushes the output buffer once it reaches
+// [SafeWriter.FlushThreshold], then the underlying writer if
+// [SafeWriter.FlushHTTP] is set and the last flush is older than
+// [SafeWriter.FlushInterval].
+func (w *SafeWriter) autoFlush() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.FlushInterval > 0 && time.Since(w.flushed) < w.FlushInterval {
+		return nil
+	}
+	return w.flushHTTP()
+}
+
+// flushHTTP flushes the underlying writer, if it implements [net/http.Flusher]
+// and [SafeWriter.FlushHTTP] is set.
+func (w *SafeWriter) flushHTTP() error {
+	f, ok := w.dst.(flusher)
+	if !w.FlushHTTP || !ok {
+		return nil
+	}
+
+	if w.async != nil {
+		// The underlying writer must not be flushed while being written.
+		if err := w.async.wait(); err != nil {
+			return err
+		}
+	}
+	f.Flush()
+	w.flushed = time.Now()
+	return nil
+}
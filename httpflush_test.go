@@ -0,0 +1,69 @@
+package csv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flushRecorder records the content written when it is flushed.
+type flushRecorder struct {
+	strings.Builder
+	flushes []string
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes = append(f.flushes, f.String())
+}
+
+func TestSafeWriterFlushHTTP(t *testing.T) {
+	is := assert.New(t)
+
+	dst := &flushRecorder{}
+	w := NewSafeWriter(dst, EscapeAll)
+	w.FlushThreshold = 4
+	w.MustWrite([]string{"a"})
+	w.MustWrite([]string{"bc"})
+	w.MustFlush()
+	is.Empty(dst.flushes)
+
+	w.FlushHTTP = true
+	w.MustWrite([]string{"d"})
+	w.MustWrite([]string{"ef"})
+	w.MustWrite([]string{"=g"})
+	w.MustFlush()
+	is.Equal([]string{"a\nbc\nd\nef\n", "a\nbc\nd\nef\n\" =g\"\n", "a\nbc\nd\nef\n\" =g\"\n"}, dst.flushes)
+
+	dst = &flushRecorder{}
+	w = NewSafeWriter(dst, EscapeAll)
+	w.FlushThreshold = 1
+	w.FlushHTTP = true
+	w.FlushInterval = time.Hour
+	w.MustWriteAll([][]string{{"a"}, {"b"}})
+	w.MustWrite([]string{"c"})
+	w.MustWrite([]string{"d"})
+	is.Equal([]string{"a\nb\n"}, dst.flushes)
+	is.Equal("a\nb\nc\nd\n", dst.String())
+	w.MustFlush()
+	is.Equal([]string{"a\nb\n", "a\nb\nc\nd\n"}, dst.flushes)
+}
+
+func TestSafeWriterFlushHTTPHandler(t *testing.T) {
+	is := assert.New(t)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := NewSafeWriter(rw, EscapeAll)
+		w.FlushHTTP = true
+		w.MustWrite([]string{"=a"})
+		w.MustFlush()
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export.csv", nil))
+	is.True(rec.Flushed)
+	is.Equal("\" =a\"\n", rec.Body.String())
+}
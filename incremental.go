@@ -0,0 +1,179 @@
+package csv
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Values of the change column written by a [DeltaWriter].
+const (
+	ChangeAdded   = "added"
+	ChangeChanged = "changed"
+	ChangeRemoved = "removed"
+)
+
+// A Manifest holds the hashes of the records of an export, by key, so that
+// the next export can emit only the records that changed, see
+// [DeltaWriter]. Keys are the JSON encoding of the key fields of the
+// records.
+type Manifest map[string]string
+
+// ReadManifest reads a manifest saved by [Manifest.Save].
+func ReadManifest(r io.Reader) (Manifest, error) {
+	m := Manifest{}
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save writes the manifest to w, as JSON.
+func (m Manifest) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+// A DeltaWriter writes to a [SafeWriter] only the records that differ from
+// a previous export, given its [Manifest], for incremental feeds. Every
+// record is prefixed by a change column: [ChangeAdded] for a key missing
+// from the previous export, [ChangeChanged] for a key whose record differs,
+// and, on Close, [ChangeRemoved] for every key of the previous export that
+// has not been written, with the key fields only.
+//
+// The exported fields can be changed before the first call to Write.
+type DeltaWriter struct {
+	// ChangeHeader is the name of the change column in the header
+	// (set to "change" by NewDeltaWriter).
+	ChangeHeader string
+
+	w        *SafeWriter
+	previous Manifest
+	next     Manifest
+	keys     []int
+	width    int // Number of fields of the header
+}
+
+// NewDeltaWriter returns a DeltaWriter writing to w the records that differ
+// from the export of previous, which may be nil for a first export. Records
+// are identified by their fields at the positions of keys, which must be
+// unique in the export.
+func NewDeltaWriter(w *SafeWriter, previous Manifest, keys ...int) *DeltaWriter {
+	return &DeltaWriter{
+		ChangeHeader: "change",
+		w:            w,
+		previous:     previous,
+		next:         Manifest{},
+		keys:         keys,
+	}
+}
+
+// WriteHeader writes the header, prefixed by the change column, with
+// [SafeWriter.WriteHeader]. It must be called before the first record is
+// written.
+func (d *DeltaWriter) WriteHeader(header []string) error {
+	d.width = len(header)
+	return d.w.WriteHeader(append([]string{d.ChangeHeader}, header...))
+}
+
+// Write writes record if it differs from the previous export, and adds it
+// to the next manifest. A changed record rejected by the [SafeWriter] keeps
+// its previous hash in the next manifest. A key already written is reported as a
+// [ColumnError] wrapping [ErrDuplicateValue].
+func (d *DeltaWriter) Write(record []string) error {
+	key := d.key(record)
+	if _, ok := d.next[key]; ok {
+		column := 0
+		if len(d.keys) > 0 {
+			column = d.keys[0]
+		}
+		return &ColumnError{
+			Row:      d.w.stats.Records,
+			Column:   column,
+			Name:     fieldAt(d.w.input, column+1),
+			Value:    key,
+			Err:      ErrDuplicateValue,
+			Metadata: d.w.Metadata,
+		}
+	}
+
+	hash := recordHash(record)
+	change := ChangeAdded
+	previous, ok := d.previous[key]
+	if ok {
+		if previous == hash {
+			d.next[key] = hash
+			return nil
+		}
+		change = ChangeChanged
+	}
+
+	if err := d.w.Write(append([]string{change}, record...)); err != nil {
+		// The record still exists: keep its previous hash so that Close
+		// does not report it as removed and the next export retries it.
+		if ok {
+			d.next[key] = previous
+		}
+		return err
+	}
+	d.next[key] = hash
+	return nil
+}
+
+// Close writes the removed records, sorted by key, then closes the
+// [SafeWriter] with [SafeWriter.Close].
+func (d *DeltaWriter) Close() error {
+	var removed []string
+	for key := range d.previous {
+		if _, ok := d.next[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, key := range removed {
+		var fields []string
+		if err := json.Unmarshal([]byte(key), &fields); err != nil {
+			return err
+		}
+
+		record := make([]string, d.width)
+		for i, n := range d.keys {
+			if n >= len(record) {
+				record = append(record, make([]string, n-len(record)+1)...)
+			}
+			if i < len(fields) {
+				record[n] = fields[i]
+			}
+		}
+		if err := d.w.Write(append([]string{ChangeRemoved}, record...)); err != nil {
+			return err
+		}
+	}
+	return d.w.Close()
+}
+
+// Manifest returns the manifest of the records written so far, to be saved
+// for the next export.
+func (d *DeltaWriter) Manifest() Manifest {
+	m := make(Manifest, len(d.next))
+	for key, hash := range d.next {
+		m[key] = hash
+	}
+	return m
+}
+
+// key returns the manifest key of record.
+func (d *DeltaWriter) key(record []string) string {
+	fields := make([]string, len(d.keys))
+	for i, n := range d.keys {
+		fields[i] = fieldAt(record, n)
+	}
+	b, _ := json.Marshal(fields)
+	return string(b)
+}
+
+// recordHash returns the hash of record stored in manifests.
+func recordHash(record []string) string {
+	b, _ := json.Marshal(record)
+	return rowKey(b)
+}
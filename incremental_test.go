@@ -0,0 +1,57 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeltaWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	d := NewDeltaWriter(NewSafeWriter(&buff, EscapeAll), nil, 0)
+	is.NoError(d.WriteHeader([]string{"id", "name", "email"}))
+	is.NoError(d.Write([]string{"1", "alice", "a@example.com"}))
+	is.NoError(d.Write([]string{"2", "bob", "b@example.com"}))
+	is.NoError(d.Write([]string{"3", "carol", "c@example.com"}))
+	is.NoError(d.Close())
+	is.Equal("change,id,name,email\nadded,1,alice,a@example.com\nadded,2,bob,b@example.com\nadded,3,carol,c@example.com\n", buff.String())
+
+	var saved bytes.Buffer
+	is.NoError(d.Manifest().Save(&saved))
+	previous, err := ReadManifest(&saved)
+	is.NoError(err)
+	is.Len(previous, 3)
+
+	buff.Reset()
+	d = NewDeltaWriter(NewSafeWriter(&buff, EscapeAll), previous, 0)
+	d.ChangeHeader = "op"
+	is.NoError(d.WriteHeader([]string{"id", "name", "email"}))
+	is.NoError(d.Write([]string{"3", "carol", "c@example.com"}))
+	is.NoError(d.Write([]string{"1", "=alice", "a@example.com"}))
+	is.NoError(d.Write([]string{"4", "dave", "d@example.com"}))
+	err = d.Write([]string{"4", "dave", "d@example.com"})
+	is.ErrorIs(err, ErrDuplicateValue)
+	is.EqualError(err, `csv: record 3, column 0 ("id"): duplicate value`)
+	is.NoError(d.Close())
+	is.Equal("op,id,name,email\nchanged,1,\" =alice\",a@example.com\nadded,4,dave,d@example.com\nremoved,2,,\n", buff.String())
+	is.Len(d.Manifest(), 3)
+
+	buff.Reset()
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {}, {Required: true}}
+	d = NewDeltaWriter(w, previous, 0)
+	is.NoError(d.WriteHeader([]string{"id", "name", "email"}))
+	is.NoError(d.Write([]string{"1", "alice", "a@example.com"}))
+	is.ErrorIs(d.Write([]string{"2", "", "b@example.com"}), ErrRequiredField)
+	is.NoError(d.Write([]string{"3", "carol", "c@example.com"}))
+	is.NoError(d.Close())
+	is.Equal("change,id,name,email\n", buff.String())
+	is.Equal(previous, d.Manifest())
+
+	_, err = ReadManifest(strings.NewReader("{"))
+	is.Error(err)
+}
@@ -0,0 +1,229 @@
+package csv
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// FileInfo describes an existing CSV file, as reported by [Inspect].
+type FileInfo struct {
+	Encoding string // "utf-8", "utf-16le" or "utf-16be"
+	BOM      bool   // True when the file starts with a byte order mark
+	// ValidUTF8 is false when some fields are not valid UTF-8 once decoded,
+	// which usually means a legacy encoding such as Windows-1252.
+	ValidUTF8 bool
+
+	Comma rune // Detected field delimiter, among , ; \t and |
+
+	// Line terminators outside quoted fields, by kind. More than one
+	// non-zero count means mixed line endings.
+	CRLFLines int64
+	LFLines   int64
+	CRLines   int64
+
+	Records      int64 // Number of records, header included
+	QuotedFields int64 // Number of fields enclosed in quotes
+	MinFields    int   // Smallest number of fields of a record
+	MaxFields    int   // Largest number of fields of a record
+	// RaggedRecords is the number of records whose number of fields
+	// differs from the one of the first record.
+	RaggedRecords int64
+
+	// Findings are the fields escaped by [EscapeAll] or matching
+	// [DefaultSignatures].
+	Findings []Finding
+}
+
+// sniffSize is the size of the beginning of the input used to detect the
+// delimiter.
+const sniffSize = 64 << 10
+
+// Inspect reads a CSV input in a single pass and reports its encoding, line
+// endings, delimiter, quoting, ragged records and injection findings. It is
+// the read-side companion of the dialect options of [SafeWriter], to audit
+// files before they are processed or appended to.
+//
+// Records are read leniently, like [SafeCopy] does, so that dirty inputs can
+// be inspected. An error is only returned when the input cannot be read.
+func Inspect(r io.Reader) (FileInfo, error) {
+	bom := &bomReader{r: bufio.NewReader(r)}
+	decoded := bufio.NewReaderSize(bom, sniffSize)
+	prefix, err := decoded.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return FileInfo{}, err
+	}
+	whole := err == io.EOF
+
+	info := FileInfo{Encoding: "utf-8", BOM: bom.bom, ValidUTF8: true}
+	switch {
+	case bom.utf16 && bom.big:
+		info.Encoding = "utf-16be"
+	case bom.utf16:
+		info.Encoding = "utf-16le"
+	}
+	info.Comma = sniffComma(prefix)
+
+	tap := &lineTap{r: decoded, comma: byte(info.Comma), start: true}
+	sr := newLenientReader(tap, info.Comma)
+	sr.BareCR = hasBareCR(prefix, whole)
+	sr.Detect = EscapeAll
+	sr.Signatures = DefaultSignatures()
+
+	var first int
+	for {
+		record, findings, err := sr.ReadWithFindings()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return info, err
+		}
+
+		if info.Records == 0 || len(record) < info.MinFields {
+			info.MinFields = len(record)
+		}
+		if len(record) > info.MaxFields {
+			info.MaxFields = len(record)
+		}
+		if info.Records == 0 {
+			first = len(record)
+		} else if len(record) != first {
+			info.RaggedRecords++
+		}
+		info.Records++
+
+		for _, field := range record {
+			if !utf8.ValidString(field) {
+				info.ValidUTF8 = false
+			}
+		}
+		info.Findings = append(info.Findings, findings...)
+	}
+
+	tap.finish()
+	info.CRLFLines, info.LFLines, info.CRLines = tap.crlf, tap.lf, tap.cr
+	info.QuotedFields = tap.quoted
+	return info, nil
+}
+
+// sniffComma returns the candidate delimiter found the most often outside
+// quoted fields in the first line of prefix, or ',' if none is found.
+func sniffComma(prefix []byte) rune {
+	candidates := []byte{',', ';', '\t', '|'}
+	counts := make([]int, len(candidates))
+
+	quoted := false
+	for _, c := range prefix {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if quoted {
+			continue
+		}
+		if c == '\n' || c == '\r' {
+			break
+		}
+		for i, candidate := range candidates {
+			if c == candidate {
+				counts[i]++
+			}
+		}
+	}
+
+	best := 0
+	for i := range candidates {
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+	return rune(candidates[best])
+}
+
+// hasBareCR reports whether prefix holds a \r that is not followed by \n.
+// A \r ending prefix only counts when prefix is the whole input.
+func hasBareCR(prefix []byte, whole bool) bool {
+	for i, c := range prefix {
+		if c != '\r' {
+			continue
+		}
+		if i+1 < len(prefix) && prefix[i+1] != '\n' || i+1 == len(prefix) && whole {
+			return true
+		}
+	}
+	return false
+}
+
+// lineTap counts the line terminators and quoted fields of the data read
+// from r, tracking quotes so that the content of quoted fields is ignored.
+type lineTap struct {
+	r     io.Reader
+	comma byte
+
+	start      bool // At the start of a field
+	inQuotes   bool // In a quoted field
+	afterQuote bool // Right after the closing quote of a field
+	afterCR    bool // Right after a \r
+
+	crlf, lf, cr int64
+	quoted       int64
+}
+
+func (t *lineTap) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	for _, c := range p[:n] {
+		t.scan(c)
+	}
+	return n, err
+}
+
+// scan updates the counters with the next byte of the input.
+func (t *lineTap) scan(c byte) {
+	if t.inQuotes {
+		t.inQuotes = c != '"'
+		t.afterQuote = !t.inQuotes
+		return
+	}
+	if t.afterQuote {
+		t.afterQuote = false
+		if c == '"' {
+			// Doubled quote within a quoted field.
+			t.inQuotes = true
+			return
+		}
+	}
+
+	if t.afterCR {
+		t.afterCR = false
+		t.start = true
+		if c == '\n' {
+			t.crlf++
+			return
+		}
+		t.cr++
+	}
+
+	switch {
+	case c == '\r':
+		t.afterCR = true
+	case c == '\n':
+		t.lf++
+		t.start = true
+	case c == t.comma:
+		t.start = true
+	case c == '"' && t.start:
+		t.quoted++
+		t.inQuotes = true
+		t.start = false
+	default:
+		t.start = false
+	}
+}
+
+// finish counts a \r ending the input.
+func (t *lineTap) finish() {
+	if t.afterCR {
+		t.afterCR = false
+		t.cr++
+	}
+}
@@ -0,0 +1,54 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	is := assert.New(t)
+
+	info, err := Inspect(strings.NewReader("\xef\xbb\xbfid;name;note\r\n1;\"Smith; \"\"J\"\"\";\"a\r\nb\"\r\n2;=cmd|' /C calc'!A0\n3;x;y;z\r\n"))
+	is.NoError(err)
+	is.Equal(FileInfo{
+		Encoding:      "utf-8",
+		BOM:           true,
+		ValidUTF8:     true,
+		Comma:         ';',
+		CRLFLines:     3,
+		LFLines:       1,
+		Records:       4,
+		QuotedFields:  2,
+		MinFields:     2,
+		MaxFields:     4,
+		RaggedRecords: 2,
+		Findings: []Finding{
+			{Row: 2, Column: 1, Line: 4, Value: "=cmd|' /C calc'!A0", Trigger: '=', Signature: "cmd-pipe"},
+		},
+	}, info)
+
+	info, err = Inspect(strings.NewReader("\xff\xfe" + encodeUTF16("a\tb\rc\td\r", false)))
+	is.NoError(err)
+	is.Equal("utf-16le", info.Encoding)
+	is.True(info.BOM)
+	is.Equal('\t', info.Comma)
+	is.EqualValues(2, info.CRLines)
+	is.EqualValues(2, info.Records)
+	is.Zero(info.RaggedRecords)
+
+	info, err = Inspect(strings.NewReader("caf\xe9,1\n"))
+	is.NoError(err)
+	is.False(info.ValidUTF8)
+	is.False(info.BOM)
+	is.Equal(',', info.Comma)
+
+	info, err = Inspect(strings.NewReader(""))
+	is.NoError(err)
+	is.Equal(FileInfo{Encoding: "utf-8", ValidUTF8: true, Comma: ','}, info)
+
+	is.Equal('|', sniffComma([]byte("\"a,b,c\"|d|e\nf,g,h,i")))
+	is.False(hasBareCR([]byte("a\r"), false))
+	is.True(hasBareCR([]byte("a\r"), true))
+}
@@ -0,0 +1,31 @@
+//go:build go1.23
+// +build go1.23
+
+package csv
+
+import "iter"
+
+// WriteSeq writes every record of seq to w using [SafeWriter.Write], then
+// flushes w, returning any error from the Flush. The iteration stops at the
+// first error.
+func (w *SafeWriter) WriteSeq(seq iter.Seq[[]string]) error {
+	for record := range seq {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteSeq2 writes every record of seq to w like [SafeWriter.WriteSeq],
+// ignoring the indexes, such as those of [slices.All].
+func (w *SafeWriter) WriteSeq2(seq iter.Seq2[int, []string]) error {
+	return w.WriteSeq(func(yield func([]string) bool) {
+		for _, record := range seq {
+			if !yield(record) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+//go:build go1.23
+// +build go1.23
+
+package csv
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSeq(t *testing.T) {
+	is := assert.New(t)
+
+	records := [][]string{{"a", "=b"}, {"c", ""}, {"d", "e"}}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.NoError(w.WriteSeq(slices.Values(records)))
+	is.NoError(w.WriteSeq2(slices.All(records[:1])))
+	is.Equal("a,\" =b\"\nc,\nd,e\na,\" =b\"\n", buff.String())
+
+	pulled := 0
+	w.Columns = []ColumnOpts{{}, {Required: true}}
+	err := w.WriteSeq2(func(yield func(int, []string) bool) {
+		for i, record := range records {
+			pulled++
+			if !yield(i, record) {
+				return
+			}
+		}
+	})
+	is.ErrorIs(err, ErrRequiredField)
+	is.Equal(2, pulled)
+}
@@ -0,0 +1,27 @@
+//go:build !tinygo && !safecsv_noreflect
+// +build !tinygo,!safecsv_noreflect
+
+package csv
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+func init() {
+	features = append(features, FeatureProfileLabels)
+}
+
+// doLabeled calls f with ctx tagged with the pprof labels of the export
+// name.
+func doLabeled(ctx context.Context, name string, f func(ctx context.Context)) {
+	pprof.Do(ctx, pprof.Labels("csv_export", name, "csv_rows", "0+"), f)
+}
+
+// labelRows updates the "csv_rows" pprof label each time the number of rows
+// reaches a power of ten.
+func labelRows(ctx context.Context, rows int64) {
+	if label, ok := rowsLabel(rows); ok {
+		pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels("csv_rows", label)))
+	}
+}
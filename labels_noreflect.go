@@ -0,0 +1,17 @@
+//go:build tinygo || safecsv_noreflect
+// +build tinygo safecsv_noreflect
+
+package csv
+
+import "context"
+
+// This file replaces labels.go in builds without reflection, as
+// runtime/pprof pulls the profiler in.
+
+// doLabeled calls f with ctx, without profiling labels.
+func doLabeled(ctx context.Context, name string, f func(ctx context.Context)) {
+	f(ctx)
+}
+
+// labelRows does nothing without profiling labels.
+func labelRows(ctx context.Context, rows int64) {}
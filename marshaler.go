@@ -0,0 +1,44 @@
+//go:build go1.18
+// +build go1.18
+
+package csv
+
+import "fmt"
+
+// RegisterTypeMarshaler registers the rendering of the values of type T,
+// such as Money, UUID or enum types, used by [SafeWriter.WriteStruct] and
+// the columns created by [ColumnFor], so that they are written the same
+// way by every export of a codebase. A marshaler registered later for the
+// same type replaces the previous one. When T is an interface type, the
+// marshaler applies to all the types implementing it.
+//
+// Rendered values are sanitized like any string field. RegisterTypeMarshaler
+// is meant to be called from init functions, and is safe for concurrent
+// use.
+func RegisterTypeMarshaler[T any](marshal func(T) (string, error)) {
+	registerMarshaler(typeMarshaler{
+		matches: func(v interface{}) bool {
+			_, ok := v.(T)
+			return ok
+		},
+		marshal: func(v interface{}) (string, error) {
+			return marshal(v.(T))
+		},
+	})
+}
+
+// ColumnFor returns a column of a [TypedEncoder] holding the value returned
+// by get, rendered by the marshaler registered for its type, or like the
+// fmt package does when there is none.
+func ColumnFor[T any, F any](name string, get func(T) F) ColumnOf[T] {
+	return ColumnOf[T]{
+		Name: name,
+		Marshal: func(v T) (string, error) {
+			value := get(v)
+			if text, ok, err := marshalRegistered(value); ok {
+				return text, err
+			}
+			return fmt.Sprint(value), nil
+		},
+	}
+}
@@ -0,0 +1,69 @@
+//go:build go1.18 && !tinygo && !safecsv_noreflect
+// +build go1.18,!tinygo,!safecsv_noreflect
+
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMoney struct {
+	Cents    int64
+	Currency string
+}
+
+type testStatus int
+
+type testLabeled interface{ Label() string }
+
+type testBadge string
+
+func (b testBadge) Label() string { return "badge:" + string(b) }
+
+func TestRegisterTypeMarshaler(t *testing.T) {
+	is := assert.New(t)
+
+	errNegative := errors.New("negative status")
+	RegisterTypeMarshaler(func(m testMoney) (string, error) {
+		return fmt.Sprintf("%d.%02d %s", m.Cents/100, m.Cents%100, m.Currency), nil
+	})
+	RegisterTypeMarshaler(func(s testStatus) (string, error) { return "ignored", nil })
+	RegisterTypeMarshaler(func(s testStatus) (string, error) {
+		if s < 0 {
+			return "", errNegative
+		}
+		return []string{"active", "=disabled"}[s], nil
+	})
+	RegisterTypeMarshaler(func(l testLabeled) (string, error) { return l.Label(), nil })
+
+	type order struct {
+		Total  testMoney  `csv:"total"`
+		Status testStatus `csv:"status"`
+		Badge  testBadge  `csv:"badge"`
+	}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Flatten = FlattenOpts{MaxDepth: 1}
+	header, err := w.Flatten.StructHeader(order{})
+	is.NoError(err)
+	is.NoError(w.WriteHeader(header))
+	is.NoError(w.WriteStruct(order{Total: testMoney{Cents: 1250, Currency: "EUR"}, Status: 1, Badge: "gold"}))
+	is.ErrorIs(w.WriteStruct(order{Status: -1}), errNegative)
+
+	enc := NewTypedEncoder([]ColumnOf[order]{
+		ColumnFor("total", func(o order) testMoney { return o.Total }),
+		ColumnFor("status", func(o order) testStatus { return o.Status }),
+		ColumnFor("count", func(o order) int { return 3 }),
+	})
+	is.NoError(enc.Encode(w, order{Total: testMoney{Cents: 5, Currency: "USD"}}))
+	is.ErrorIs(enc.Encode(w, order{Status: -1}), errNegative)
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("total,status,badge\n12.50 EUR,\" =disabled\",badge:gold\n0.05 USD,active,3\n", buff.String())
+}
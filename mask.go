@@ -0,0 +1,64 @@
+package csv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redact returns a [Transformer] replacing every non-empty field by
+// placeholder, such as "[REDACTED]".
+func Redact(placeholder string) Transformer {
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+		return placeholder, nil
+	}
+}
+
+// Mask returns a [Transformer] replacing every character of a field by mask,
+// except the last keep ones, such as 4111111111111111 as ************1111.
+// Empty fields are left untouched, and a negative keep masks every character.
+func Mask(mask rune, keep int) Transformer {
+	if keep < 0 {
+		keep = 0
+	}
+	return func(field string) (string, error) {
+		n := utf8.RuneCountInString(field) - keep
+		if n <= 0 {
+			return field, nil
+		}
+
+		i := 0
+		for masked := 0; masked < n; masked++ {
+			_, size := utf8.DecodeRuneInString(field[i:])
+			i += size
+		}
+		return strings.Repeat(string(mask), n) + field[i:], nil
+	}
+}
+
+// Hash returns a [Transformer] replacing every non-empty field by the
+// hexadecimal HMAC-SHA256 of its value with key, so that equal values remain
+// equal and can still be joined. A SHA-256 digest is used when key is empty,
+// which does not protect low-entropy values such as phone numbers against
+// brute force.
+func Hash(key []byte) Transformer {
+	return func(field string) (string, error) {
+		if field == "" {
+			return field, nil
+		}
+
+		if len(key) == 0 {
+			sum := sha256.Sum256([]byte(field))
+			return hex.EncodeToString(sum[:]), nil
+		}
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(field))
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+}
@@ -0,0 +1,56 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// transformed returns the output of transform for field.
+func transformed(t *testing.T, transform Transformer, field string) string {
+	t.Helper()
+
+	out, err := transform(field)
+	assert.NoError(t, err)
+	return out
+}
+
+func TestRedact(t *testing.T) {
+	is := assert.New(t)
+
+	redact := Redact("[REDACTED]")
+	is.Equal("[REDACTED]", transformed(t, redact, "alice@example.com"))
+	is.Equal("", transformed(t, redact, ""))
+}
+
+func TestMask(t *testing.T) {
+	is := assert.New(t)
+
+	mask := Mask('*', 4)
+	is.Equal("************1111", transformed(t, mask, "4111111111111111"))
+	is.Equal("***é45", transformed(t, Mask('*', 3), "café45"))
+	is.Equal("123", transformed(t, mask, "123"))
+	is.Equal("", transformed(t, mask, ""))
+	is.Equal("•••", transformed(t, Mask('•', 0), "abc"))
+	is.Equal("***", transformed(t, Mask('*', -2), "abc"))
+	is.Equal("", transformed(t, Mask('*', -2), ""))
+}
+
+func TestHash(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("2bd806c97f0e00af1a1fc3328fa763a9269723c8db8fac4f93af71db186d6e90", transformed(t, Hash(nil), "alice"))
+	is.Equal("", transformed(t, Hash(nil), ""))
+
+	keyed := transformed(t, Hash([]byte("secret")), "alice")
+	is.Len(keyed, 64)
+	is.NotEqual(transformed(t, Hash(nil), "alice"), keyed)
+	is.Equal(keyed, transformed(t, Hash([]byte("secret")), "alice"))
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {Transformers: []Transformer{Mask('-', 2)}}}
+	is.NoError(w.WriteAll([][]string{{"alice", "0612345678"}}))
+	is.Equal("alice,\" --------78\"\n", buff.String())
+}
@@ -0,0 +1,26 @@
+package csv
+
+// MustWrite is like [SafeWriter.Write] but panics if the record cannot be
+// written. It is meant for scripts, examples and test fixtures.
+func (w *SafeWriter) MustWrite(record []string) {
+	if err := w.Write(record); err != nil {
+		panic(err)
+	}
+}
+
+// MustWriteAll is like [SafeWriter.WriteAll] but panics if the records cannot
+// be written.
+func (w *SafeWriter) MustWriteAll(records [][]string) {
+	if err := w.WriteAll(records); err != nil {
+		panic(err)
+	}
+}
+
+// MustFlush calls [SafeWriter.Flush] and panics if [SafeWriter.Error] then
+// reports an error.
+func (w *SafeWriter) MustFlush() {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		panic(err)
+	}
+}
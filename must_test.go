@@ -0,0 +1,28 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMust(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.MustWrite([]string{"id", "name"})
+	w.MustWriteAll([][]string{{"1", "=a"}})
+	w.MustWrite([]string{"2", "b"})
+	w.MustFlush()
+	is.Equal("id,name\n1,\" =a\"\n2,b\n", buff.String())
+
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.PanicsWithError(`csv: record 3, column 0: required field is empty`, func() { w.MustWrite([]string{""}) })
+	is.Panics(func() { w.MustWriteAll([][]string{{""}}) })
+
+	w = NewSafeWriter(&errorWriter{}, EscapeAll)
+	w.MustWrite([]string{"a"})
+	is.PanicsWithError("Test", w.MustFlush)
+}
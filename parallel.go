@@ -0,0 +1,133 @@
+package csv
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParallelOpts configures [SafeWriter.WriteAllParallel].
+type ParallelOpts struct {
+	// Workers is the number of goroutines encoding records
+	// (runtime.GOMAXPROCS(0) when 0).
+	Workers int
+	// ChunkSize is the number of records encoded by a goroutine at once
+	// (1024 when 0).
+	ChunkSize int
+}
+
+// WriteAllParallel writes multiple CSV records to w like
+// [SafeWriter.WriteAll], encoding chunks of records on several goroutines.
+// Chunks are written in order, so the output, the returned error and the
+// [Stats] are the same as with [SafeWriter.WriteAll], including when a
+// record is rejected or [SafeWriter.MaxErrors] is exceeded.
+//
+// On top of the records, memory usage is bounded by the encoded size of
+// Workers × ChunkSize records. Transformers and computed columns are called
+// concurrently, and must be safe for concurrent use.
+//
+// Records are written by [SafeWriter.WriteAll] instead when a column is
+// unique, since uniqueness is checked across chunks, and when
+// [SafeWriter.Audit] or [SafeWriter.Quarantine] is set, since records are
+// reported in order.
+func (w *SafeWriter) WriteAllParallel(records [][]string, opts ParallelOpts) error {
+	if w.hasUniqueColumns() || w.Audit != nil || w.Quarantine != nil {
+		return w.WriteAll(records)
+	}
+	if w.Envelope != nil && w.opened.IsZero() {
+		if err := w.writeEnvelope(nil); err != nil {
+			return err
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = 1024
+	}
+
+	for len(records) > 0 {
+		round := len(records)
+		if round > workers*size {
+			round = workers * size
+		}
+
+		encoders := w.encodeChunks(records[:round], size)
+		records = records[round:]
+
+		for _, e := range encoders {
+			if err := w.writeChunk(e); err != nil {
+				return err
+			}
+		}
+	}
+	return w.flush()
+}
+
+// chunkEncoder is a copy of a SafeWriter encoding a chunk of records.
+type chunkEncoder struct {
+	w    SafeWriter
+	base Stats // Stats of the copy before encoding the chunk
+	err  error
+}
+
+// encodeChunks encodes records on a goroutine per chunk of size records.
+func (w *SafeWriter) encodeChunks(records [][]string, size int) []*chunkEncoder {
+	var encoders []*chunkEncoder
+	var wg sync.WaitGroup
+
+	for start := 0; start < len(records); start += size {
+		end := start + size
+		if end > len(records) {
+			end = len(records)
+		}
+
+		e := &chunkEncoder{w: *w}
+		e.w.fields, e.w.buf, e.w.projected = nil, nil, nil
+		e.w.batch = true
+		e.w.FlushThreshold = 0
+		e.base = w.stats
+		e.base.Records += int64(start)
+		e.w.stats = e.base
+		encoders = append(encoders, e)
+
+		wg.Add(1)
+		go func(e *chunkEncoder, chunk [][]string) {
+			defer wg.Done()
+			for _, record := range chunk {
+				if e.err = e.w.Write(record); e.err != nil {
+					return
+				}
+			}
+		}(e, records[start:end])
+	}
+
+	wg.Wait()
+	return encoders
+}
+
+// writeChunk writes the records encoded by e and merges its stats, then
+// returns the error that stopped it, if any. Since a chunk stops at its first
+// rejected record, and is only written once the previous chunks succeeded,
+// the MaxErrors budget of the copy starts from the rejections of w.
+func (w *SafeWriter) writeChunk(e *chunkEncoder) error {
+	w.buf = e.w.buf
+	if err := w.writeBuffer(); err != nil {
+		return err
+	}
+	w.buf = nil
+
+	w.stats.Records = e.w.stats.Records
+	w.stats.TrustedRecords += e.w.stats.TrustedRecords - e.base.TrustedRecords
+	w.stats.SanitizedFields += e.w.stats.SanitizedFields - e.base.SanitizedFields
+	w.stats.RejectedRecords += e.w.stats.RejectedRecords - e.base.RejectedRecords
+	if w.started.IsZero() {
+		w.started = e.w.started
+	}
+	if e.w.aborted != nil {
+		w.aborted = e.w.aborted
+	}
+	return e.err
+}
@@ -0,0 +1,119 @@
+package csv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeWriterWriteAllParallel(t *testing.T) {
+	is := assert.New(t)
+
+	records := make([][]string, 100)
+	for i := range records {
+		records[i] = []string{fmt.Sprint(i), "=" + fmt.Sprint(i)}
+	}
+
+	var expected, actual strings.Builder
+	is.NoError(NewSafeWriter(&expected, EscapeAll).WriteAll(records))
+
+	w := NewSafeWriter(&actual, EscapeAll)
+	is.NoError(w.WriteAllParallel(records, ParallelOpts{Workers: 3, ChunkSize: 7}))
+	is.Equal(expected.String(), actual.String())
+	is.Equal(Stats{Records: 100, SanitizedFields: 100}, w.Stats())
+
+	records[50][1] = ""
+	actual.Reset()
+	w = NewSafeWriter(&actual, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {Required: true}}
+	err := w.WriteAllParallel(records, ParallelOpts{Workers: 4, ChunkSize: 3})
+	is.Equal(&ColumnError{Row: 50, Column: 1, Value: "", Err: ErrRequiredField}, err)
+	is.Equal(Stats{Records: 50, SanitizedFields: 50, RejectedRecords: 1}, w.Stats())
+
+	w.Flush()
+	is.Equal(strings.Join(strings.SplitAfter(expected.String(), "\n")[:50], ""), actual.String())
+}
+
+func TestSafeWriterWriteAllParallelMaxErrors(t *testing.T) {
+	is := assert.New(t)
+
+	records := [][]string{{"1"}, {""}, {"2"}, {""}, {"3"}}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.MaxErrors = 1
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.ErrorIs(w.WriteAllParallel(records, ParallelOpts{Workers: 2, ChunkSize: 1}), ErrRequiredField)
+	is.ErrorIs(w.WriteAllParallel(records[2:], ParallelOpts{Workers: 2, ChunkSize: 1}), ErrTooManyErrors)
+	is.ErrorIs(w.WriteAllParallel(records[4:], ParallelOpts{Workers: 2, ChunkSize: 1}), ErrTooManyErrors)
+	w.Flush()
+	is.ErrorIs(w.Error(), ErrTooManyErrors)
+	is.Equal("1\n2\n", buff.String())
+	is.Equal(Stats{Records: 2, RejectedRecords: 2}, w.Stats())
+}
+
+func TestSafeWriterWriteAllParallelMatchesWriteAll(t *testing.T) {
+	is := assert.New(t)
+
+	batches := make([][][]string, 4)
+	for i := range batches {
+		for j := 0; j < 20; j++ {
+			batches[i] = append(batches[i], []string{fmt.Sprint(i*20 + j), "ok"})
+		}
+	}
+	batches[1][5][1] = "=cmd|' /C calc'!A0"
+	batches[2][12][1] = "@SUM(1)"
+
+	// Quarantine makes WriteAllParallel fall back to WriteAll, so both
+	// paths are compared.
+	for _, quarantine := range []bool{false, true} {
+		run := func(write func(w *SafeWriter, records [][]string) error) (string, string, []error, ExportSummary) {
+			var buff, rejected, report strings.Builder
+
+			opts := EscapeAll
+			opts.Strategy = StrategyReject
+			w := NewSafeWriter(&buff, opts)
+			w.MaxErrors = 1
+			w.Summary = &report
+			if quarantine {
+				w.Quarantine = NewSafeWriter(&rejected, EscapeAll)
+			}
+			is.NoError(w.WriteHeader([]string{"id", "cmd"}))
+
+			var errs []error
+			for _, records := range batches {
+				errs = append(errs, write(w, records))
+			}
+			errs = append(errs, w.Close())
+			if quarantine {
+				w.Quarantine.Flush()
+			}
+
+			var summary ExportSummary
+			is.NoError(json.Unmarshal([]byte(report.String()), &summary))
+			summary.Started, summary.Duration = summary.Started.UTC(), 0
+			return buff.String(), rejected.String(), errs, summary
+		}
+
+		out, rejected, errs, summary := run(func(w *SafeWriter, records [][]string) error {
+			return w.WriteAll(records)
+		})
+		is.ErrorIs(errs[1], ErrDangerousField)
+		is.ErrorIs(errs[2], ErrTooManyErrors)
+		is.Equal(int64(2), summary.RejectedRecords)
+
+		for _, size := range []int{1, 3, 7, 64} {
+			pout, prejected, perrs, psummary := run(func(w *SafeWriter, records [][]string) error {
+				return w.WriteAllParallel(records, ParallelOpts{Workers: 3, ChunkSize: size})
+			})
+			is.Equal(out, pout, size)
+			is.Equal(rejected, prejected, size)
+			is.Equal(errs, perrs, size)
+			psummary.Started = summary.Started
+			is.Equal(summary, psummary, size)
+		}
+	}
+}
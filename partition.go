@@ -0,0 +1,135 @@
+package csv
+
+import (
+	"errors"
+	"io"
+)
+
+var errMissingPartitionKey = errors.New("csv: record has no partition key")
+
+// A PartitionedWriter routes records to several outputs according to the
+// value of a key column, such as one file per country. The header, if any,
+// is written at the top of every output.
+//
+// The exported fields apply to every output, and must be set before the
+// first write.
+type PartitionedWriter struct {
+	Comma   rune // Field delimiter (set to ',' by NewPartitionedWriter)
+	UseCRLF bool // True to use \r\n as the line terminator
+
+	// Metadata is attached to errors, see [SafeWriter.Metadata].
+	Metadata map[string]string
+
+	column     int
+	opts       SafetyOpts
+	create     func(key string) (io.WriteCloser, error)
+	header     []string
+	partitions map[string]*partition
+	keys       []string // Keys of the partitions, in creation order
+}
+
+// partition is an output of a PartitionedWriter.
+type partition struct {
+	out io.WriteCloser
+	w   *SafeWriter
+}
+
+// NewPartitionedWriter returns a new PartitionedWriter routing records by the
+// value of their field at index column. create is called to open the output
+// of a key when its first record is written. The key is the raw value of the
+// field, before sanitization: create must validate it before using it in a
+// file name.
+func NewPartitionedWriter(column int, opts SafetyOpts, create func(key string) (io.WriteCloser, error)) *PartitionedWriter {
+	return &PartitionedWriter{
+		Comma:      ',',
+		column:     column,
+		opts:       opts,
+		create:     create,
+		partitions: map[string]*partition{},
+	}
+}
+
+// WriteHeader sets the header written at the top of every output. It must
+// be called before the first record is written.
+func (p *PartitionedWriter) WriteHeader(header []string) error {
+	if len(p.keys) > 0 {
+		return errHeaderAfterRecords
+	}
+	p.header = append([]string{}, header...)
+	return nil
+}
+
+// Write writes a single record to the output of its key, creating it if
+// needed.
+func (p *PartitionedWriter) Write(record []string) error {
+	if p.column < 0 || p.column >= len(record) {
+		return errMissingPartitionKey
+	}
+
+	key := record[p.column]
+	part, ok := p.partitions[key]
+	if !ok {
+		var err error
+		if part, err = p.open(key); err != nil {
+			return err
+		}
+	}
+	return part.w.Write(record)
+}
+
+// open creates the output of key and writes the header to it.
+func (p *PartitionedWriter) open(key string) (*partition, error) {
+	out, err := p.create(key)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &partition{out: out, w: NewSafeWriter(out, p.opts)}
+	part.w.Comma = p.Comma
+	part.w.UseCRLF = p.UseCRLF
+	part.w.Metadata = p.Metadata
+	p.partitions[key] = part
+	p.keys = append(p.keys, key)
+
+	if p.header != nil {
+		return part, part.w.WriteHeader(p.header)
+	}
+	return part, nil
+}
+
+// Flush writes any buffered data of every output.
+func (p *PartitionedWriter) Flush() error {
+	var err error
+	for _, key := range p.keys {
+		w := p.partitions[key].w
+		w.Flush()
+		if werr := w.Error(); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// Close flushes and closes every output, in creation order. It returns the
+// first error, after having closed all of them.
+func (p *PartitionedWriter) Close() error {
+	var err error
+	for _, key := range p.keys {
+		part := p.partitions[key]
+		part.w.Flush()
+		if werr := part.w.Error(); err == nil {
+			err = werr
+		}
+		if cerr := part.out.Close(); err == nil {
+			err = cerr
+		}
+	}
+	p.partitions, p.keys = map[string]*partition{}, nil
+	return err
+}
+
+// Partitions returns the keys of the outputs created so far, in creation
+// order.
+func (p *PartitionedWriter) Partitions() []string {
+	return append([]string{}, p.keys...)
+}
@@ -0,0 +1,47 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionedWriter(t *testing.T) {
+	is := assert.New(t)
+
+	partitions := map[string]*closingBuilder{}
+	w := NewPartitionedWriter(1, EscapeAll, func(key string) (io.WriteCloser, error) {
+		is.NotContains(partitions, key)
+		partitions[key] = &closingBuilder{}
+		return partitions[key], nil
+	})
+	w.Comma = ';'
+
+	is.NoError(w.WriteHeader([]string{"id", "country"}))
+	for _, record := range [][]string{{"1", "fr"}, {"=2", "us"}, {"3", "fr"}} {
+		is.NoError(w.Write(record))
+	}
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errHeaderAfterRecords)
+	is.ErrorIs(w.Write([]string{"4"}), errMissingPartitionKey)
+	is.Equal([]string{"fr", "us"}, w.Partitions())
+
+	is.NoError(w.Flush())
+	is.Equal("id;country\n1;fr\n3;fr\n", partitions["fr"].String())
+	is.False(partitions["fr"].closed)
+
+	is.NoError(w.Close())
+	is.Equal("id;country\n\" =2\";us\n", partitions["us"].String())
+	is.True(partitions["fr"].closed)
+	is.True(partitions["us"].closed)
+	is.Empty(w.Partitions())
+
+	errCreate := errors.New("cannot create")
+	w = NewPartitionedWriter(0, EscapeAll, func(key string) (io.WriteCloser, error) {
+		return nil, errCreate
+	})
+	is.ErrorIs(w.Write([]string{"a"}), errCreate)
+	is.Empty(w.Partitions())
+	is.NoError(w.Close())
+}
@@ -0,0 +1,218 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// A Source is a stream of records, such as a [SafeReader] or an
+// [encoding/csv.Reader]. Read returns [io.EOF] after the last record.
+type Source interface {
+	Read() ([]string, error)
+}
+
+// A Stage is a named step of a [Pipeline]. Record, if set, rewrites the
+// whole record and may return a nil record to drop it. Field, if set, is then
+// applied to every field of the record.
+type Stage struct {
+	Name   string
+	Record func(record []string) ([]string, error)
+	Field  Transformer
+}
+
+// A StageError is returned when a stage of a [Pipeline] fails.
+type StageError struct {
+	Stage string // Name of the stage
+	Index int    // Index of the stage in the pipeline, starting at 0
+	Row   int64  // Index of the record in the source, starting at 0
+	Err   error  // The actual error
+
+	// Metadata of the writer of the pipeline, see [SafeWriter.Metadata].
+	Metadata map[string]string
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("csv: stage %d (%q), record %d: %v%s", e.Index, e.Stage, e.Row, e.Err, formatMetadata(e.Metadata))
+}
+
+func (e *StageError) Unwrap() error { return e.Err }
+
+// A Pipeline reads records from a [Source], passes them through its stages
+// in order, then writes them to a [SafeWriter], which sanitizes them.
+//
+// While running, the goroutine is tagged with the pprof labels "csv_export",
+// holding [Pipeline.Name], and "csv_rows", holding the order of magnitude of
+// the records read so far (such as "1000+"), so that CPU profiles of
+// concurrent exports can be told apart. Builds without reflection do not
+// set the labels.
+//
+// A Pipeline runs either synchronously with [Pipeline.Run], or on its own
+// goroutine with [Pipeline.Start], which must then be followed by
+// [Pipeline.Wait], [Pipeline.Close] or [Pipeline.Shutdown].
+type Pipeline struct {
+	Name   string // Name of the export, for profiling
+	Source Source
+	Stages []Stage
+	Writer *SafeWriter
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	rows   int64
+	err    error
+}
+
+// NewPipeline returns a new Pipeline copying records from src to w.
+func NewPipeline(src Source, w *SafeWriter, stages ...Stage) *Pipeline {
+	return &Pipeline{
+		Source: src,
+		Stages: stages,
+		Writer: w,
+	}
+}
+
+// Run copies every record of the source to the writer, until the source is
+// exhausted, a step fails or ctx is done. The writer is flushed in any case.
+// It returns the number of records read from the source.
+//
+// Errors of the stages are returned as a [StageError]. Errors of the source
+// and the writer are returned unchanged.
+func (p *Pipeline) Run(ctx context.Context) (int64, error) {
+	var rows int64
+	var err error
+	doLabeled(ctx, p.Name, func(ctx context.Context) {
+		err = p.run(ctx, &rows)
+	})
+
+	p.Writer.Flush()
+	if err == nil {
+		err = p.Writer.Error()
+	}
+	return rows, err
+}
+
+// Start runs the pipeline on a new goroutine, as [Pipeline.Run] does. It
+// must be called at most once.
+func (p *Pipeline) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		defer p.cancel()
+		p.rows, p.err = p.Run(ctx)
+	}()
+}
+
+// Wait waits for a pipeline started by [Pipeline.Start] to finish, and
+// returns the result of [Pipeline.Run], or zero values if it was never
+// started.
+func (p *Pipeline) Wait() (int64, error) {
+	if p.done == nil {
+		return 0, nil // Never started
+	}
+	<-p.done
+	return p.rows, p.err
+}
+
+// Shutdown stops a pipeline started by [Pipeline.Start] and waits for its
+// goroutine to exit, or for ctx to be done, whichever happens first. The
+// pipeline stops before reading the next record, so a source blocked in Read
+// delays it. Shutdown returns ctx.Err() if ctx is done first, and nil
+// otherwise; the result of the run is then returned by [Pipeline.Wait]. It
+// returns nil right away if the pipeline was never started.
+func (p *Pipeline) Shutdown(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil // Never started
+	}
+	p.cancel()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops a pipeline started by [Pipeline.Start] and waits for its
+// goroutine to exit. It returns nil if the pipeline was never started.
+func (p *Pipeline) Close() error {
+	return p.Shutdown(context.Background())
+}
+
+func (p *Pipeline) run(ctx context.Context, rows *int64) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := p.Source.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		row := *rows
+		*rows++
+		labelRows(ctx, *rows)
+
+		record, err = p.transform(record, row)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			continue
+		}
+
+		if err := p.Writer.Write(record); err != nil {
+			return err
+		}
+	}
+}
+
+// transform passes record through every stage.
+func (p *Pipeline) transform(record []string, row int64) ([]string, error) {
+	for i, stage := range p.Stages {
+		var err error
+		if stage.Record != nil {
+			record, err = stage.Record(record)
+			if err != nil {
+				return nil, p.stageError(i, row, err)
+			}
+			if record == nil {
+				return nil, nil
+			}
+		}
+
+		if stage.Field != nil {
+			for n, field := range record {
+				record[n], err = stage.Field(field)
+				if err != nil {
+					return nil, p.stageError(i, row, err)
+				}
+			}
+		}
+	}
+	return record, nil
+}
+
+// stageError returns a [StageError] for the failure of the stage at index i.
+func (p *Pipeline) stageError(i int, row int64, err error) error {
+	return &StageError{Stage: p.Stages[i].Name, Index: i, Row: row, Err: err, Metadata: p.Writer.Metadata}
+}
+
+// rowsLabel returns the "csv_rows" label for rows, if rows is a power of ten.
+func rowsLabel(rows int64) (string, bool) {
+	n := rows
+	for n > 1 && n%10 == 0 {
+		n /= 10
+	}
+	if n != 1 {
+		return "", false
+	}
+	return strconv.FormatInt(rows, 10) + "+", true
+}
@@ -0,0 +1,162 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	p := NewPipeline(
+		NewSafeReader(strings.NewReader("id,name\n1,=a\n2,skip\n3,b\n")),
+		NewSafeWriter(&buff, EscapeAll),
+		Stage{
+			Name: "filter",
+			Record: func(record []string) ([]string, error) {
+				if record[1] == "skip" {
+					return nil, nil
+				}
+				return record, nil
+			},
+		},
+		Stage{Name: "upper", Field: func(field string) (string, error) { return strings.ToUpper(field), nil }},
+	)
+
+	n, err := p.Run(context.Background())
+	is.NoError(err)
+	is.EqualValues(4, n)
+	is.Equal("ID,NAME\n1,\" =A\"\n3,B\n", buff.String())
+}
+
+func TestPipelineStageError(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	errBoom := errors.New("boom")
+
+	p := NewPipeline(
+		NewSafeReader(strings.NewReader("a\nb\nc\n")),
+		NewSafeWriter(&buff, EscapeAll),
+		Stage{Name: "noop"},
+		Stage{
+			Name: "fail",
+			Field: func(field string) (string, error) {
+				if field == "b" {
+					return "", errBoom
+				}
+				return field, nil
+			},
+		},
+	)
+
+	n, err := p.Run(context.Background())
+	is.EqualValues(2, n)
+	is.ErrorIs(err, errBoom)
+	is.Equal(&StageError{Stage: "fail", Index: 1, Row: 1, Err: errBoom}, err)
+	is.EqualError(err, `csv: stage 1 ("fail"), record 1: boom`)
+	is.Equal("a\n", buff.String())
+}
+
+func TestPipelineCancel(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := NewPipeline(
+		NewSafeReader(strings.NewReader("a\nb\nc\n")),
+		NewSafeWriter(&buff, EscapeAll),
+		Stage{
+			Name: "cancel",
+			Record: func(record []string) ([]string, error) {
+				cancel()
+				return record, nil
+			},
+		},
+	)
+
+	n, err := p.Run(ctx)
+	is.EqualValues(1, n)
+	is.ErrorIs(err, context.Canceled)
+	is.Equal("a\n", buff.String())
+}
+
+func TestPipelineLabels(t *testing.T) {
+	is := assert.New(t)
+
+	p := NewPipeline(NewSafeReader(strings.NewReader(strings.Repeat("a\n", 12))), NewSafeWriter(io.Discard, EscapeAll))
+	p.Name = "users"
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("service", "api"))
+	n, err := p.Run(ctx)
+	is.NoError(err)
+	is.EqualValues(12, n)
+
+	_, ok := pprof.Label(ctx, "csv_export")
+	is.False(ok)
+
+	for rows, expected := range map[int64]string{1: "1+", 10: "10+", 1000: "1000+", 0: "", 2: "", 20: "", 1001: ""} {
+		label, ok := rowsLabel(rows)
+		is.Equal(expected, label)
+		is.Equal(expected != "", ok)
+	}
+}
+
+// blockingSource blocks in Read until it is released.
+type blockingSource struct {
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSource) Read() ([]string, error) {
+	close(s.reading)
+	<-s.release
+	return nil, io.EOF
+}
+
+func TestPipelineStart(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	p := NewPipeline(NewSafeReader(strings.NewReader("a\n=b\n")), NewSafeWriter(&buff, EscapeAll))
+	p.Start(context.Background())
+
+	n, err := p.Wait()
+	is.NoError(err)
+	is.EqualValues(2, n)
+	is.Equal("a\n\" =b\"\n", buff.String())
+	is.NoError(p.Close())
+
+	src := &blockingSource{reading: make(chan struct{}), release: make(chan struct{})}
+	p = NewPipeline(src, NewSafeWriter(io.Discard, EscapeAll))
+	p.Start(context.Background())
+	<-src.reading
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	is.ErrorIs(p.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(src.release)
+	is.NoError(p.Close())
+	n, err = p.Wait()
+	is.NoError(err)
+	is.EqualValues(0, n)
+
+	// never started
+	p = NewPipeline(src, NewSafeWriter(io.Discard, EscapeAll))
+	is.NoError(p.Shutdown(context.Background()))
+	is.NoError(p.Close())
+	n, err = p.Wait()
+	is.NoError(err)
+	is.EqualValues(0, n)
+}
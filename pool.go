@@ -0,0 +1,43 @@
+package csv
+
+import "sync"
+
+var recordPool = sync.Pool{
+	New: func() interface{} { return new([]string) },
+}
+
+// GetRecord returns a record of n empty fields from a pool, so that
+// producers of many rows do not allocate a slice per row. The record should
+// be given back with [PutRecord] once written.
+//
+// A [SafeWriter] never keeps a reference to the records it is given, so a
+// record can be reused or put back as soon as Write returns.
+func GetRecord(n int) []string {
+	p := recordPool.Get().(*[]string)
+	record := *p
+	*p = nil
+	holderPool.Put(p)
+
+	if cap(record) < n {
+		return make([]string, n)
+	}
+
+	record = record[:n]
+	for i := range record {
+		record[i] = ""
+	}
+	return record
+}
+
+// PutRecord puts record back in the pool used by [GetRecord]. The record
+// must not be used afterwards.
+func PutRecord(record []string) {
+	p := holderPool.Get().(*[]string)
+	*p = record[:0]
+	recordPool.Put(p)
+}
+
+// holderPool recycles the pointers stored in the pool of records.
+var holderPool = sync.Pool{
+	New: func() interface{} { return new([]string) },
+}
@@ -0,0 +1,31 @@
+package csv
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRecord(t *testing.T) {
+	is := assert.New(t)
+
+	record := GetRecord(3)
+	is.Equal([]string{"", "", ""}, record)
+
+	record[0], record[1], record[2] = "a", "b", "c"
+	PutRecord(record)
+
+	record = GetRecord(2)
+	is.Equal([]string{"", ""}, record)
+	PutRecord(record)
+
+	w := NewSafeWriter(io.Discard, FullSafety)
+	allocs := testing.AllocsPerRun(100, func() {
+		record := GetRecord(2)
+		record[0], record[1] = "a", "=b"
+		_ = w.Write(record)
+		PutRecord(record)
+	})
+	is.LessOrEqual(allocs, 2.0)
+}
@@ -0,0 +1,39 @@
+package csv
+
+import "fmt"
+
+// QuarantineColumn is the name of the column holding the reason of the
+// rejection, in the header written to [SafeWriter.Quarantine].
+const QuarantineColumn = "error"
+
+// quarantine writes record, rejected with err, to the quarantine writer, if
+// any.
+func (w *SafeWriter) quarantine(record []string, err error) error {
+	if w.Quarantine == nil {
+		return nil
+	}
+
+	row := make([]string, 0, len(record)+1)
+	row = append(append(row, record...), err.Error())
+	if qerr := w.Quarantine.Write(row); qerr != nil {
+		return fmt.Errorf("csv: quarantine: %w", qerr)
+	}
+	return nil
+}
+
+// quarantineHeader writes the header of the input records, followed by
+// QuarantineColumn, to the quarantine writer, unless it already holds
+// records.
+func (w *SafeWriter) quarantineHeader() error {
+	q := w.Quarantine
+	if q == nil || q.header != nil || q.stats.Records > 0 {
+		return nil
+	}
+
+	header := make([]string, 0, len(w.input)+1)
+	header = append(append(header, w.input...), QuarantineColumn)
+	if err := q.WriteHeader(header); err != nil {
+		return fmt.Errorf("csv: quarantine: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuarantine(t *testing.T) {
+	is := assert.New(t)
+
+	var buff, rejected strings.Builder
+
+	opts := EscapeAll
+	opts.Strategy = StrategyReject
+	w := NewSafeWriter(&buff, opts)
+	w.Quarantine = NewSafeWriter(&rejected, EscapeAll)
+	w.Columns = []ColumnOpts{{Required: true}}
+	w.SelectColumns("id", "cmd")
+	is.NoError(w.WriteHeader([]string{"id", "name", "cmd"}))
+	is.NoError(w.Write([]string{"1", "alice", "ok"}))
+	is.ErrorIs(w.Write([]string{"2", "bob", "=cmd|' /C calc'!A0"}), ErrDangerousField)
+	is.ErrorIs(w.WriteCells([]Cell{StringCell(""), StringCell("carol"), StringCell("ok")}), ErrRequiredField)
+	w.Flush()
+	w.Quarantine.Flush()
+	is.NoError(w.Error())
+	is.NoError(w.Quarantine.Error())
+	is.Equal("id,cmd\n1,ok\n", buff.String())
+	is.Equal(`id,name,cmd,error
+2,bob," =cmd|' /C calc'!A0","csv: record 2, column 1 (""cmd""): field would be interpreted as a formula"
+,carol,ok,"csv: record 2, column 0 (""id""): required field is empty"
+`, rejected.String())
+	is.EqualValues(2, w.Stats().RejectedRecords)
+
+	rejected.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Quarantine = NewSafeWriter(&rejected, EscapeAll)
+	w.Quarantine.Columns = []ColumnOpts{{Required: true}}
+	w.Columns = []ColumnOpts{{Allowed: []string{"a"}}}
+	is.NoError(w.Quarantine.WriteHeader([]string{"value", "reason"}))
+	is.NoError(w.WriteHeader([]string{"id"}))
+	is.EqualError(w.Write([]string{""}), `csv: quarantine: csv: record 1, column 0 ("value"): required field is empty`)
+	w.Quarantine.Flush()
+	is.Equal("value,reason\n", rejected.String())
+}
@@ -0,0 +1,278 @@
+package csv
+
+import (
+	"bufio"
+	stdcsv "encoding/csv"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// A SafeReader reads records from a CSV-encoded file, wrapping the
+// [encoding/csv.Reader] of the standard library, whose exported fields can be
+// changed before the first call to Read.
+//
+// A leading UTF-8 byte order mark is removed, and inputs starting with a
+// UTF-16 byte order mark are converted to UTF-8, so that the name of the
+// first column is never corrupted.
+//
+// To parse dirty inputs, set LazyQuotes to accept quotes in unquoted fields
+// and non-doubled quotes in quoted fields, FieldsPerRecord to -1 to accept
+// records of varying lengths, and [SafeReader.BareCR] to accept files using
+// \r alone as their line terminator.
+type SafeReader struct {
+	*stdcsv.Reader
+
+	// BareCR makes a \r that is not followed by \n end the line, as in files
+	// written by old Mac software. Such characters in quoted fields are read
+	// as \n too.
+	BareCR bool
+
+	// Detect lists the leading characters reported as findings by
+	// [SafeReader.ReadWithFindings]. ForceDoubleQuotes is ignored. Nothing
+	// is reported when zero.
+	Detect SafetyOpts
+
+	// Signatures, when not nil, reports the fields matching a signature of
+	// the set as findings too, wherever the payload is in the field, such
+	// as with [DefaultSignatures].
+	Signatures *SignatureSet
+
+	// Quarantine, when not empty, replaces the fields matching Detect or
+	// Signatures by
+	// this placeholder, such as "[REMOVED: formula]", in every record read.
+	Quarantine string
+
+	// Audit, when not nil, is called with every finding, holding the
+	// original value, including those of records read by Read or ReadAll.
+	Audit func(Finding)
+
+	row int64
+}
+
+// A Finding reports a field of an untrusted input that would be interpreted
+// as a formula by spreadsheet software, or that holds a known payload.
+type Finding struct {
+	Row       int64  // Index of the record in the input, starting at 0
+	Column    int    // Index of the field in the record, starting at 0
+	Line      int    // Line of the field in the input, starting at 1
+	Value     string // Offending value
+	Trigger   rune   // Leading character triggering the finding, if any
+	Signature string // Name of the signature matching the field, if any
+}
+
+// NewSafeReader returns a new SafeReader that reads from r.
+func NewSafeReader(r io.Reader) *SafeReader {
+	sr := &SafeReader{}
+	sr.Reader = stdcsv.NewReader(&crReader{
+		r:       bufio.NewReader(&bomReader{r: bufio.NewReader(r)}),
+		enabled: &sr.BareCR,
+	})
+	return sr
+}
+
+// crReader replaces bare \r with \n when enabled.
+type crReader struct {
+	r       *bufio.Reader
+	enabled *bool
+}
+
+func (c *crReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if !*c.enabled {
+		return n, err
+	}
+
+	for i := 0; i < n; i++ {
+		if p[i] != '\r' {
+			continue
+		}
+
+		if i+1 < n {
+			if p[i+1] != '\n' {
+				p[i] = '\n'
+			}
+		} else if next, err := c.r.Peek(1); err != nil || next[0] != '\n' {
+			p[i] = '\n'
+		}
+	}
+	return n, err
+}
+
+// bomReader removes the byte order mark of its input, transcoding UTF-16
+// to UTF-8.
+type bomReader struct {
+	r       *bufio.Reader
+	checked bool
+	bom     bool // True when a byte order mark was found
+	utf16   bool
+	big     bool // Big-endian UTF-16
+	pending []byte
+}
+
+func (b *bomReader) Read(p []byte) (int, error) {
+	if !b.checked {
+		b.checked = true
+		if err := b.detect(); err != nil {
+			return 0, err
+		}
+	}
+
+	if !b.utf16 {
+		return b.r.Read(p)
+	}
+	return b.readUTF16(p)
+}
+
+// detect consumes the byte order mark, if any.
+func (b *bomReader) detect() error {
+	bom, err := b.r.Peek(3)
+	if err == io.EOF {
+		err = nil
+	} else if err != nil {
+		return err
+	}
+
+	switch {
+	case len(bom) >= 3 && bom[0] == 0xEF && bom[1] == 0xBB && bom[2] == 0xBF:
+		b.bom = true
+		_, err = b.r.Discard(3)
+	case len(bom) >= 2 && bom[0] == 0xFF && bom[1] == 0xFE:
+		b.bom, b.utf16 = true, true
+		_, err = b.r.Discard(2)
+	case len(bom) >= 2 && bom[0] == 0xFE && bom[1] == 0xFF:
+		b.bom, b.utf16, b.big = true, true, true
+		_, err = b.r.Discard(2)
+	}
+	return err
+}
+
+// readUTF16 reads UTF-16 code units and returns them encoded as UTF-8.
+func (b *bomReader) readUTF16(p []byte) (int, error) {
+	for len(b.pending) < len(p) {
+		r, err := b.readRune()
+		if err != nil {
+			if len(b.pending) > 0 {
+				break
+			}
+			return 0, err
+		}
+
+		var buf [utf8.UTFMax]byte
+		b.pending = append(b.pending, buf[:utf8.EncodeRune(buf[:], r)]...)
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// readRune decodes the next rune of the UTF-16 input.
+func (b *bomReader) readRune() (rune, error) {
+	r1, err := b.readUnit()
+	if err != nil {
+		return 0, err
+	}
+
+	if !utf16.IsSurrogate(r1) {
+		return r1, nil
+	}
+
+	r2, err := b.readUnit()
+	if err != nil {
+		return utf8.RuneError, nil
+	}
+	return utf16.DecodeRune(r1, r2), nil
+}
+
+// readUnit reads a single UTF-16 code unit.
+func (b *bomReader) readUnit() (rune, error) {
+	var unit [2]byte
+	if _, err := io.ReadFull(b.r, unit[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return utf8.RuneError, nil
+		}
+		return 0, err
+	}
+
+	if b.big {
+		return rune(unit[0])<<8 | rune(unit[1]), nil
+	}
+	return rune(unit[1])<<8 | rune(unit[0]), nil
+}
+
+// Read reads one record from r. See [encoding/csv.Reader.Read].
+func (r *SafeReader) Read() ([]string, error) {
+	record, _, err := r.read(false)
+	return record, err
+}
+
+// ReadAll reads all the remaining records from r.
+// See [encoding/csv.Reader.ReadAll].
+func (r *SafeReader) ReadAll() ([][]string, error) {
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// ReadWithFindings reads one record from r, along with the findings of its
+// fields according to [SafeReader.Detect] and [SafeReader.Signatures].
+// Findings do not alter the record, unless [SafeReader.Quarantine] is set.
+func (r *SafeReader) ReadWithFindings() ([]string, []Finding, error) {
+	return r.read(true)
+}
+
+func (r *SafeReader) read(detect bool) ([]string, []Finding, error) {
+	record, err := r.Reader.Read()
+	if err != nil {
+		return record, nil, err
+	}
+
+	row := r.row
+	r.row++
+
+	if !detect && r.Quarantine == "" && r.Audit == nil {
+		return record, nil, nil
+	}
+
+	var findings []Finding
+	for i, field := range record {
+		trigger, triggered := r.Detect.trigger(field)
+		var signature string
+		if r.Signatures != nil {
+			signature, _ = r.Signatures.Match(field)
+		}
+		if !triggered && signature == "" {
+			continue
+		}
+
+		line, _ := r.Reader.FieldPos(i)
+		finding := Finding{
+			Row:       row,
+			Column:    i,
+			Line:      line,
+			Value:     field,
+			Trigger:   trigger,
+			Signature: signature,
+		}
+
+		if r.Audit != nil {
+			r.Audit(finding)
+		}
+		if r.Quarantine != "" {
+			record[i] = r.Quarantine
+		}
+		if detect {
+			findings = append(findings, finding)
+		}
+	}
+	return record, findings, nil
+}
@@ -0,0 +1,215 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeUTF16(s string, big bool) string {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		if big {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return string(b)
+}
+
+func TestSafeReaderBOM(t *testing.T) {
+	is := assert.New(t)
+
+	expected := [][]string{{"id", "name"}, {"1", "café 😀"}}
+	content := "id,name\n1,café 😀\n"
+
+	for _, input := range []string{
+		content,
+		"\xef\xbb\xbf" + content,
+		"\xff\xfe" + encodeUTF16(content, false),
+		"\xfe\xff" + encodeUTF16(content, true),
+	} {
+		records, err := NewSafeReader(strings.NewReader(input)).ReadAll()
+		is.NoError(err)
+		is.Equal(expected, records)
+	}
+
+	records, err := NewSafeReader(strings.NewReader("")).ReadAll()
+	is.NoError(err)
+	is.Empty(records)
+
+	records, err = NewSafeReader(strings.NewReader("a\n")).ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{{"a"}}, records)
+
+	records, err = NewSafeReader(strings.NewReader("\xff\xfea\x00\x00")).ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{{"a�"}}, records)
+
+	r := NewSafeReader(strings.NewReader("\xef\xbb\xbfa;b\n"))
+	r.Comma = ';'
+	record, err := r.Read()
+	is.NoError(err)
+	is.Equal([]string{"a", "b"}, record)
+}
+
+type chunkReader struct {
+	chunks []string
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	if len(c.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, c.chunks[0])
+	c.chunks = c.chunks[1:]
+	return n, nil
+}
+
+func TestSafeReaderLenient(t *testing.T) {
+	is := assert.New(t)
+
+	input := "id,name\r1,a\r\n2,\"b\rc\"\r3,d\"e\r"
+
+	r := NewSafeReader(strings.NewReader(input))
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{{"id", "name\r1", "a"}, {"2", "b\rc\"\r3,d\"e"}}, records)
+
+	r = NewSafeReader(strings.NewReader(input))
+	r.LazyQuotes = true
+	r.BareCR = true
+	records, err = r.ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{{"id", "name"}, {"1", "a"}, {"2", "b\nc"}, {"3", "d\"e"}}, records)
+
+	r = NewSafeReader(&chunkReader{chunks: []string{"a\r", "\nb\r", "c\r"}})
+	r.BareCR = true
+	records, err = r.ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{{"a"}, {"b"}, {"c"}}, records)
+
+	var buff strings.Builder
+	_, err = SafeCopy(&buff, strings.NewReader("a,=b\rc,d\r"), EscapeAll, CopyOpts{BareCR: true})
+	is.NoError(err)
+	is.Equal("a,\" =b\"\nc,d\n", buff.String())
+}
+
+func TestSafeReaderFindings(t *testing.T) {
+	is := assert.New(t)
+
+	r := NewSafeReader(strings.NewReader("id,formula\n1,=cmd|' /C calc'!A0\n\"2\n\",@SUM(A1)\n3,-1\n"))
+	r.Detect = SafetyOpts{EscapeCharEqual: true, EscapeCharAt: true}
+
+	record, findings, err := r.ReadWithFindings()
+	is.NoError(err)
+	is.Equal([]string{"id", "formula"}, record)
+	is.Empty(findings)
+
+	record, err = r.Read()
+	is.NoError(err)
+	is.Equal([]string{"1", "=cmd|' /C calc'!A0"}, record)
+
+	_, findings, err = r.ReadWithFindings()
+	is.NoError(err)
+	is.Equal([]Finding{{Row: 2, Column: 1, Line: 4, Value: "@SUM(A1)", Trigger: '@'}}, findings)
+
+	_, findings, err = r.ReadWithFindings()
+	is.NoError(err)
+	is.Empty(findings)
+
+	_, _, err = r.ReadWithFindings()
+	is.Equal(io.EOF, err)
+
+	records, err := NewSafeReader(strings.NewReader("a,\"b")).ReadAll()
+	is.Error(err)
+	is.Nil(records)
+}
+
+func TestSafeReaderFindingsPaths(t *testing.T) {
+	is := assert.New(t)
+
+	r := NewSafeReader(strings.NewReader("\\\\evil\\share\\x,File://evil/x,ok\n"))
+	r.Detect = SafetyOpts{EscapeUNCPaths: true, EscapeFileURLs: true}
+
+	_, findings, err := r.ReadWithFindings()
+	is.NoError(err)
+	is.Equal([]Finding{
+		{Row: 0, Column: 0, Line: 1, Value: `\\evil\share\x`, Trigger: '\\'},
+		{Row: 0, Column: 1, Line: 1, Value: "File://evil/x", Trigger: 'F'},
+	}, findings)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, r.Detect)
+	is.NoError(w.Write([]string{`\\evil\share\x`, "file://evil/x", `a\\b`}))
+	w.Flush()
+	is.Equal("\" \\\\evil\\share\\x\",\" file://evil/x\",a\\\\b\n", buff.String())
+}
+
+func TestSafeReaderFindingsTriggerRunes(t *testing.T) {
+	is := assert.New(t)
+
+	r := NewSafeReader(strings.NewReader("|cmd,＝1+1,%x\n"))
+	r.Detect = SafetyOpts{TriggerRunes: []rune{'|', '＝'}}
+
+	_, findings, err := r.ReadWithFindings()
+	is.NoError(err)
+	is.Equal([]Finding{
+		{Row: 0, Column: 0, Line: 1, Value: "|cmd", Trigger: '|'},
+		{Row: 0, Column: 1, Line: 1, Value: "＝1+1", Trigger: '＝'},
+	}, findings)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, r.Detect)
+	is.NoError(w.Write([]string{"|cmd", "＝1+1", "%x"}))
+	w.Flush()
+	is.Equal("\" |cmd\",\" ＝1+1\",%x\n", buff.String())
+}
+
+func TestSafeReaderSignatures(t *testing.T) {
+	is := assert.New(t)
+
+	var audit []Finding
+	r := NewSafeReader(strings.NewReader("a,b\n=cmd|' /C calc'!A0,x cmd|y\n"))
+	r.Detect = SafetyOpts{EscapeCharEqual: true}
+	r.Signatures = DefaultSignatures()
+	r.Quarantine = "[REMOVED]"
+	r.Audit = func(f Finding) { audit = append(audit, f) }
+
+	records, err := r.ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{{"a", "b"}, {"[REMOVED]", "[REMOVED]"}}, records)
+	is.Equal([]Finding{
+		{Row: 1, Column: 0, Line: 2, Value: "=cmd|' /C calc'!A0", Trigger: '=', Signature: "cmd-pipe"},
+		{Row: 1, Column: 1, Line: 2, Value: "x cmd|y", Signature: "cmd-pipe"},
+	}, audit)
+}
+
+func TestSafeReaderQuarantine(t *testing.T) {
+	is := assert.New(t)
+
+	var audit []Finding
+	r := NewSafeReader(strings.NewReader("name,total\n=HYPERLINK(A1),+1\n-,ok\n"))
+	r.Detect = SafetyOpts{EscapeCharEqual: true, EscapeCharPlus: true}
+	r.Quarantine = "[REMOVED: formula]"
+	r.Audit = func(f Finding) { audit = append(audit, f) }
+
+	records, err := r.ReadAll()
+	is.NoError(err)
+	is.Equal([][]string{
+		{"name", "total"},
+		{"[REMOVED: formula]", "[REMOVED: formula]"},
+		{"-", "ok"},
+	}, records)
+	is.Equal([]Finding{
+		{Row: 1, Column: 0, Line: 2, Value: "=HYPERLINK(A1)", Trigger: '='},
+		{Row: 1, Column: 1, Line: 2, Value: "+1", Trigger: '+'},
+	}, audit)
+}
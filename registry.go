@@ -0,0 +1,49 @@
+package csv
+
+import "sync"
+
+// A typeMarshaler renders the values of a type registered by
+// RegisterTypeMarshaler.
+type typeMarshaler struct {
+	matches func(v interface{}) bool // Reports whether v has the type
+	marshal func(v interface{}) (string, error)
+}
+
+// marshalers holds the registered marshalers, most recent first.
+var marshalers struct {
+	sync.RWMutex
+	list []typeMarshaler
+}
+
+// registerMarshaler registers m before the marshalers registered so far.
+func registerMarshaler(m typeMarshaler) {
+	marshalers.Lock()
+	defer marshalers.Unlock()
+
+	marshalers.list = append([]typeMarshaler{m}, marshalers.list...)
+}
+
+// lookupMarshaler returns the most recent marshaler registered for the type
+// of v, if any.
+func lookupMarshaler(v interface{}) (typeMarshaler, bool) {
+	marshalers.RLock()
+	defer marshalers.RUnlock()
+
+	for _, m := range marshalers.list {
+		if m.matches(v) {
+			return m, true
+		}
+	}
+	return typeMarshaler{}, false
+}
+
+// marshalRegistered renders v with the marshaler registered for its type,
+// reporting whether there is one.
+func marshalRegistered(v interface{}) (string, bool, error) {
+	m, ok := lookupMarshaler(v)
+	if !ok {
+		return "", false, nil
+	}
+	text, err := m.marshal(v)
+	return text, true, err
+}
@@ -0,0 +1,131 @@
+package csv
+
+import "strconv"
+
+// ColumnType is the type of the values of a column.
+type ColumnType int
+
+const (
+	// TypeString accepts any value.
+	TypeString ColumnType = iota
+	// TypeInteger accepts base 10 integers.
+	TypeInteger
+	// TypeNumber accepts floating-point numbers.
+	TypeNumber
+	// TypeBoolean accepts the values understood by [strconv.ParseBool].
+	TypeBoolean
+)
+
+// String returns the name of the type.
+func (t ColumnType) String() string {
+	switch t {
+	case TypeInteger:
+		return "integer"
+	case TypeNumber:
+		return "number"
+	case TypeBoolean:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// matches reports whether a non-empty field is a valid value of type t.
+func (t ColumnType) matches(field string) bool {
+	var err error
+	switch t {
+	case TypeInteger:
+		_, err = strconv.ParseInt(field, 10, 64)
+	case TypeNumber:
+		_, err = strconv.ParseFloat(field, 64)
+	case TypeBoolean:
+		_, err = strconv.ParseBool(field)
+	}
+	return err == nil
+}
+
+// SchemaColumn describes a single column of a [Schema].
+type SchemaColumn struct {
+	Name     string
+	Type     ColumnType
+	Nullable bool // True when the column may hold empty fields
+}
+
+// A Schema describes the columns of a CSV file.
+type Schema []SchemaColumn
+
+// Columns returns the column options validating records against the schema,
+// to be used as [SafeWriter.Columns].
+func (s Schema) Columns() []ColumnOpts {
+	columns := make([]ColumnOpts, len(s))
+	for i, col := range s {
+		columns[i] = ColumnOpts{
+			Name:     col.Name,
+			Type:     col.Type,
+			Required: !col.Nullable,
+		}
+	}
+	return columns
+}
+
+// Header returns the names of the columns of the schema.
+func (s Schema) Header() []string {
+	header := make([]string, len(s))
+	for i, col := range s {
+		header[i] = col.Name
+	}
+	return header
+}
+
+// InferSchema guesses the schema of records, whose first record is the
+// header. At most n records following the header are sampled, or all of them
+// when n is not positive.
+//
+// A column gets the narrowest type matching every non-empty sampled field,
+// integers being preferred over booleans for 0 and 1. It is nullable when
+// at least one sampled field is empty.
+func InferSchema(records [][]string, n int) Schema {
+	if len(records) == 0 {
+		return Schema{}
+	}
+
+	sample := records[1:]
+	if n > 0 && n < len(sample) {
+		sample = sample[:n]
+	}
+
+	schema := make(Schema, len(records[0]))
+	for i, name := range records[0] {
+		candidates := []ColumnType{TypeInteger, TypeNumber, TypeBoolean}
+		col := SchemaColumn{Name: name}
+		seen := false
+
+		for _, record := range sample {
+			field := ""
+			if i < len(record) {
+				field = record[i]
+			}
+
+			if field == "" {
+				col.Nullable = true
+				continue
+			}
+			seen = true
+
+			kept := candidates[:0]
+			for _, t := range candidates {
+				if t.matches(field) {
+					kept = append(kept, t)
+				}
+			}
+			candidates = kept
+		}
+
+		if seen && len(candidates) > 0 {
+			col.Type = candidates[0]
+		}
+		schema[i] = col
+	}
+
+	return schema
+}
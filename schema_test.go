@@ -0,0 +1,72 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferSchema(t *testing.T) {
+	is := assert.New(t)
+
+	records := [][]string{
+		{"id", "price", "active", "name", "comment", "flag"},
+		{"1", "3.5", "true", "Alice", "", "1"},
+		{"2", "4", "false", "Bob", "", "0"},
+		{"", "-1e3", "TRUE", "42", ""},
+		{"x", "x", "x", "x", "x", "x"},
+	}
+
+	is.Equal(Schema{
+		{Name: "id", Type: TypeInteger, Nullable: true},
+		{Name: "price", Type: TypeNumber},
+		{Name: "active", Type: TypeBoolean},
+		{Name: "name", Type: TypeString},
+		{Name: "comment", Type: TypeString, Nullable: true},
+		{Name: "flag", Type: TypeInteger, Nullable: true},
+	}, InferSchema(records, 3))
+
+	is.Equal(Schema{
+		{Name: "id", Type: TypeString, Nullable: true},
+		{Name: "price", Type: TypeString},
+		{Name: "active", Type: TypeString},
+		{Name: "name", Type: TypeString},
+		{Name: "comment", Type: TypeString, Nullable: true},
+		{Name: "flag", Type: TypeString, Nullable: true},
+	}, InferSchema(records, 0))
+
+	is.Equal(Schema{}, InferSchema(nil, 10))
+	is.Equal(Schema{{Name: "a"}}, InferSchema([][]string{{"a"}}, 10))
+}
+
+func TestSchemaColumns(t *testing.T) {
+	is := assert.New(t)
+
+	schema := Schema{
+		{Name: "id", Type: TypeInteger},
+		{Name: "price", Type: TypeNumber, Nullable: true},
+	}
+	is.Equal([]string{"id", "price"}, schema.Header())
+	is.Equal([]ColumnOpts{
+		{Name: "id", Type: TypeInteger, Required: true},
+		{Name: "price", Type: TypeNumber},
+	}, schema.Columns())
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = schema.Columns()
+	is.NoError(w.WriteHeader(schema.Header()))
+	is.NoError(w.Write([]string{"1", "-2.5"}))
+	is.NoError(w.Write([]string{"2", ""}))
+	is.EqualError(w.Write([]string{"3", "free"}), `csv: record 3, column 1 ("price"): value does not match column type`)
+	is.ErrorIs(w.Write([]string{"", "1"}), ErrRequiredField)
+	w.Flush()
+	is.Equal("id,price\n1,\" -2.5\"\n2,\n", buff.String())
+
+	is.Equal("integer", TypeInteger.String())
+	is.Equal("number", TypeNumber.String())
+	is.Equal("boolean", TypeBoolean.String())
+	is.Equal("string", TypeString.String())
+}
@@ -0,0 +1,81 @@
+package csv
+
+import (
+	"regexp"
+	"sync"
+)
+
+// A Signature is a known malicious payload, matched anywhere in a field.
+type Signature struct {
+	Name    string         // Name reported in findings, such as "cmd-pipe"
+	Pattern *regexp.Regexp // Matched against the whole field
+}
+
+// A SignatureSet is a set of signatures reported as findings by
+// [SafeReader]. It is safe for concurrent use, so that signatures can be
+// added while inputs are being read, for instance from an updated feed.
+type SignatureSet struct {
+	mu         sync.RWMutex
+	signatures []Signature
+}
+
+// defaultSignatures are the payloads of known CSV injection attacks.
+var defaultSignatures = []Signature{
+	{Name: "cmd-pipe", Pattern: regexp.MustCompile(`(?i)cmd\s*\|`)},
+	{Name: "msexcel-pipe", Pattern: regexp.MustCompile(`(?i)msexcel\s*\|`)},
+	{Name: "rundll32", Pattern: regexp.MustCompile(`(?i)rundll32`)},
+	{Name: "powershell", Pattern: regexp.MustCompile(`(?i)powershell`)},
+	{Name: "mshta", Pattern: regexp.MustCompile(`(?i)mshta`)},
+	{Name: "dde", Pattern: regexp.MustCompile(`(?i)\bDDE(AUTO)?\s*\(`)},
+	{Name: "hyperlink", Pattern: regexp.MustCompile(`(?i)\bHYPERLINK\s*\(`)},
+	{Name: "webservice", Pattern: regexp.MustCompile(`(?i)\bWEBSERVICE\s*\(`)},
+	{Name: "import-function", Pattern: regexp.MustCompile(`(?i)\bIMPORT(XML|HTML|DATA|FEED|RANGE)\s*\(`)},
+	// References to other workbooks, such as [book.xlsx]Sheet1!A1.
+	{Name: "external-workbook", Pattern: regexp.MustCompile(`(?i)\[[^\]]+\.(xl[a-z]*|csv|ods)\][^!]*!`)},
+}
+
+// NewSignatureSet returns a set holding signatures.
+func NewSignatureSet(signatures ...Signature) *SignatureSet {
+	return &SignatureSet{signatures: append([]Signature{}, signatures...)}
+}
+
+// DefaultSignatures returns a new set holding the payloads of known CSV
+// injection attacks: command execution through DDE (cmd|, msexcel|,
+// rundll32, powershell, mshta), data exfiltration functions (HYPERLINK,
+// WEBSERVICE, IMPORTXML...) and references to external workbooks. Adding
+// signatures to the returned set does not alter the defaults.
+func DefaultSignatures() *SignatureSet {
+	return NewSignatureSet(defaultSignatures...)
+}
+
+// Add adds a signature matching pattern to the set.
+func (s *SignatureSet) Add(name string, pattern *regexp.Regexp) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signatures = append(s.signatures, Signature{Name: name, Pattern: pattern})
+}
+
+// AddLiteral adds a signature matching text, ignoring case, to the set.
+func (s *SignatureSet) AddLiteral(name string, text string) {
+	s.Add(name, regexp.MustCompile(`(?i)`+regexp.QuoteMeta(text)))
+}
+
+// Signatures returns the signatures of the set, in the order they were added.
+func (s *SignatureSet) Signatures() []Signature {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Signature{}, s.signatures...)
+}
+
+// Match returns the name of the first signature of the set matching field.
+func (s *SignatureSet) Match(field string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, sig := range s.signatures {
+		if sig.Pattern.MatchString(field) {
+			return sig.Name, true
+		}
+	}
+	return "", false
+}
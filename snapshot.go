@@ -0,0 +1,67 @@
+package csv
+
+import "io"
+
+// A Snapshot is the state of a [SafeWriter], as returned by
+// [SafeWriter.Snapshot], from which a write can be resumed with [Resume].
+// It can be encoded with [encoding/json] and stored alongside the output,
+// so that a crashed export job appends to its last chunk, or starts the
+// next one, without reading back what was already written.
+type Snapshot struct {
+	Stats Stats // Counters of the records written so far
+
+	Input      []string // Header of the input records, nil if none was written
+	Header     []string // Header of the output, after projection
+	Projection []int    // Positions in Input of the output columns, nil for all
+
+	Comma      rune
+	UseCRLF    bool
+	Canonical  bool
+	HeaderCase HeaderCase
+	BoolFormat BoolFormat
+	Safety     SafetyOpts
+}
+
+// Snapshot returns the state of w. It should be taken after a Flush, so
+// that the records it counts have reached the underlying [io.Writer].
+//
+// Column options, transformers and computed columns are not part of the
+// snapshot, since they hold functions: they must be set again on the
+// resumed writer, in the same order. Neither are the values tracked by
+// unique columns.
+func (w *SafeWriter) Snapshot() Snapshot {
+	return Snapshot{
+		Stats:      w.stats,
+		Input:      append([]string(nil), w.input...),
+		Header:     append([]string(nil), w.header...),
+		Projection: append([]int(nil), w.projection...),
+		Comma:      w.Comma,
+		UseCRLF:    w.UseCRLF,
+		Canonical:  w.Canonical,
+		HeaderCase: w.HeaderCase,
+		BoolFormat: w.BoolFormat,
+		Safety:     w.opts,
+	}
+}
+
+// Resume returns a new SafeWriter writing to w, in the state captured by
+// snapshot: the header is not written again, named fields are picked as
+// before, and counters and errors carry on from the records already
+// written.
+func Resume(snapshot Snapshot, w io.Writer) *SafeWriter {
+	sw := NewSafeWriter(w, snapshot.Safety)
+	sw.Comma = snapshot.Comma
+	sw.UseCRLF = snapshot.UseCRLF
+	sw.Canonical = snapshot.Canonical
+	sw.HeaderCase = snapshot.HeaderCase
+	sw.BoolFormat = snapshot.BoolFormat
+
+	sw.stats = snapshot.Stats
+	sw.input = append([]string(nil), snapshot.Input...)
+	sw.header = append([]string(nil), snapshot.Header...)
+	sw.projection = append([]int(nil), snapshot.Projection...)
+	if snapshot.Header != nil {
+		sw.headers = 1
+	}
+	return sw
+}
@@ -0,0 +1,44 @@
+package csv
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotResume(t *testing.T) {
+	is := assert.New(t)
+
+	var first strings.Builder
+	w := NewSafeWriter(&first, EscapeAll)
+	w.Comma = ';'
+	w.HeaderCase = HeaderLowerSnake
+	w.SelectColumns("name", "id")
+	is.NoError(w.WriteHeader([]string{"ID", "E-mail", "Name"}))
+	is.NoError(w.Write([]string{"1", "alice@example.com", "=Alice"}))
+	w.Flush()
+
+	b, err := json.Marshal(w.Snapshot())
+	is.NoError(err)
+	var snapshot Snapshot
+	is.NoError(json.Unmarshal(b, &snapshot))
+	is.Equal(w.Snapshot(), snapshot)
+
+	var second strings.Builder
+	w = Resume(snapshot, &second)
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.NoError(w.Write([]string{"2", "bob@example.com", "Bob"}))
+	is.NoError(w.WriteMap(map[string]string{"ID": "3", "Name": "-Carol"}))
+	is.ErrorIs(w.WriteHeader([]string{"id"}), errHeaderAfterRecords)
+
+	err = w.WriteMap(map[string]string{"id": "4"})
+	is.EqualError(err, `csv: record 4, column 0 ("name"): required field is empty`)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("name;id\n\" =Alice\";1\n", first.String())
+	is.Equal("Bob;2\n\" -Carol\";3\n", second.String())
+	is.Equal(Stats{Records: 4, SanitizedFields: 2, RejectedRecords: 1}, w.Stats())
+}
@@ -0,0 +1,355 @@
+package csv
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// A SortKey is a column used to order the records of a [SortingWriter].
+type SortKey struct {
+	Column     int  // Index of the field in the record, starting at 0
+	Descending bool // True to sort in decreasing order
+	// Numeric compares the fields as numbers when both of them can be
+	// parsed as such, and as strings when neither can. Numbers come before
+	// the other fields, such as empty ones.
+	Numeric bool
+}
+
+// A SortingWriter orders records by some columns before writing them to a
+// [SafeWriter], for exports that must be sorted when their source cannot
+// provide the ordering. Records are buffered in memory up to a limit, then
+// spilled to sorted temporary files, which are merged when Flush is called.
+// The sort is stable.
+//
+// The exported fields can be changed before the first call to Write.
+type SortingWriter struct {
+	// MaxRecords is the number of records held in memory before they are
+	// sorted and spilled to a temporary file (100000 when 0).
+	MaxRecords int
+	// TempDir is the directory of the temporary files ([os.TempDir] when
+	// empty).
+	TempDir string
+
+	w        *SafeWriter
+	keys     []SortKey
+	pending  [][]string
+	runs     []*os.File
+	err      error // Error spilling records, stopping the writer
+	flushErr error // Error of the records written by the last Flush
+}
+
+// NewSortingWriter returns a SortingWriter writing to w the records ordered
+// by keys, compared in order, as given to Write, before projection.
+func NewSortingWriter(w *SafeWriter, keys ...SortKey) *SortingWriter {
+	return &SortingWriter{
+		w:    w,
+		keys: keys,
+	}
+}
+
+// WriteHeader writes the header with [SafeWriter.WriteHeader]. It must be
+// called before the first record is written.
+func (s *SortingWriter) WriteHeader(header []string) error {
+	return s.w.WriteHeader(header)
+}
+
+// Write buffers a copy of record, spilling the buffered records to a
+// temporary file if the limit is reached. Errors writing temporary files
+// stop the writer: every later call fails.
+func (s *SortingWriter) Write(record []string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.pending = append(s.pending, append([]string{}, record...))
+	if len(s.pending) < s.maxRecords() {
+		return nil
+	}
+
+	if err := s.spill(); err != nil {
+		s.err = err
+		return err
+	}
+	return nil
+}
+
+// Flush writes all the records buffered so far, sorted, removes the
+// temporary files, then flushes the [SafeWriter]. Records written after
+// Flush are sorted on their own by the next Flush. Records rejected by the
+// [SafeWriter] are skipped. To check if an error occurred, call
+// [SortingWriter.Error].
+func (s *SortingWriter) Flush() {
+	if s.err == nil && (len(s.pending) > 0 || len(s.runs) > 0) {
+		s.flushErr = s.merge()
+	}
+	s.removeRuns()
+	s.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Flush, or that
+// stopped the writer.
+func (s *SortingWriter) Error() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.flushErr != nil {
+		return s.flushErr
+	}
+	return s.w.Error()
+}
+
+func (s *SortingWriter) maxRecords() int {
+	if s.MaxRecords <= 0 {
+		return 100000
+	}
+	return s.MaxRecords
+}
+
+// less reports whether a must be written before b.
+func (s *SortingWriter) less(a []string, b []string) bool {
+	for _, key := range s.keys {
+		if c := compareField(a, b, key); c != 0 {
+			return c < 0
+		}
+	}
+	return false
+}
+
+// compareField compares the fields of a and b at the column of key. Missing
+// fields compare as empty. With a numeric key, numbers come before the other
+// fields, so that the order stays total.
+func compareField(a []string, b []string, key SortKey) int {
+	x, y := fieldAt(a, key.Column), fieldAt(b, key.Column)
+
+	c := compareString(x, y)
+	if key.Numeric {
+		fx, okx := parseSortNumber(x)
+		fy, oky := parseSortNumber(y)
+		switch {
+		case okx && oky:
+			c = compareFloat(fx, fy)
+		case okx:
+			c = -1
+		case oky:
+			c = 1
+		}
+	}
+
+	if key.Descending {
+		return -c
+	}
+	return c
+}
+
+// parseSortNumber parses field as a number compared by a numeric key. NaN
+// is compared as a string, since it is not ordered.
+func parseSortNumber(field string) (float64, bool) {
+	f, err := strconv.ParseFloat(field, 64)
+	return f, err == nil && !math.IsNaN(f)
+}
+
+func fieldAt(record []string, n int) string {
+	if n < 0 || n >= len(record) {
+		return ""
+	}
+	return record[n]
+}
+
+func compareFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareString(a string, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// spill sorts the buffered records and writes them to a temporary file.
+func (s *SortingWriter) spill() error {
+	records := s.pending
+	s.pending = nil
+	sort.SliceStable(records, func(i, j int) bool { return s.less(records[i], records[j]) })
+
+	f, err := os.CreateTemp(s.TempDir, "safecsv-sort-*")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f)
+
+	bw := bufio.NewWriter(f)
+	for _, record := range records {
+		writeSortRecord(bw, record)
+	}
+	return bw.Flush()
+}
+
+// writeSortRecord encodes record as the number of its fields, followed by
+// every field prefixed by its length. Unlike CSV, the encoding preserves
+// line terminators within fields.
+func writeSortRecord(w *bufio.Writer, record []string) {
+	var b [binary.MaxVarintLen64]byte
+	w.Write(b[:binary.PutUvarint(b[:], uint64(len(record)))])
+	for _, field := range record {
+		w.Write(b[:binary.PutUvarint(b[:], uint64(len(field)))])
+		w.WriteString(field)
+	}
+}
+
+// readSortRecord decodes a record written by writeSortRecord.
+func readSortRecord(r *bufio.Reader) ([]string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]string, n)
+	for i := range record {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		field := make([]byte, size)
+		if _, err := io.ReadFull(r, field); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		record[i] = string(field)
+	}
+	return record, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// sortRun is a sorted sequence of records being merged, with its next
+// record.
+type sortRun struct {
+	index  int // Position of the run, breaking ties to keep the sort stable
+	next   []string
+	r      *bufio.Reader // Reader of the temporary file, nil in memory
+	memory [][]string
+}
+
+func (r *sortRun) advance() (bool, error) {
+	if r.r == nil {
+		if len(r.memory) == 0 {
+			return false, nil
+		}
+		r.next, r.memory = r.memory[0], r.memory[1:]
+		return true, nil
+	}
+
+	var err error
+	r.next, err = readSortRecord(r.r)
+	if err == io.EOF {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// sortHeap orders the runs by their next record.
+type sortHeap struct {
+	s    *SortingWriter
+	runs []*sortRun
+}
+
+func (h *sortHeap) Len() int { return len(h.runs) }
+
+func (h *sortHeap) Less(i, j int) bool {
+	a, b := h.runs[i], h.runs[j]
+	if h.s.less(a.next, b.next) {
+		return true
+	}
+	if h.s.less(b.next, a.next) {
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h *sortHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *sortHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*sortRun)) }
+
+func (h *sortHeap) Pop() interface{} {
+	run := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return run
+}
+
+// merge writes the records of the temporary files and of the buffer, in
+// order.
+func (s *SortingWriter) merge() error {
+	records := s.pending
+	s.pending = nil
+	sort.SliceStable(records, func(i, j int) bool { return s.less(records[i], records[j]) })
+
+	h := &sortHeap{s: s}
+	add := func(run *sortRun) error {
+		ok, err := run.advance()
+		if ok {
+			h.runs = append(h.runs, run)
+		}
+		return err
+	}
+
+	for i, f := range s.runs {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := add(&sortRun{index: i, r: bufio.NewReader(f)}); err != nil {
+			return err
+		}
+	}
+	if err := add(&sortRun{index: len(s.runs), memory: records}); err != nil {
+		return err
+	}
+	heap.Init(h)
+
+	var first error
+	for h.Len() > 0 {
+		run := h.runs[0]
+		if err := s.w.Write(run.next); err != nil && first == nil {
+			first = err
+		}
+
+		ok, err := run.advance()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return first
+}
+
+// removeRuns closes and removes the temporary files.
+func (s *SortingWriter) removeRuns() {
+	for _, f := range s.runs {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	s.runs = nil
+}
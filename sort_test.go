@@ -0,0 +1,70 @@
+package csv
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortingWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	dir := t.TempDir()
+	w := NewSortingWriter(NewSafeWriter(&buff, EscapeAll), SortKey{Column: 1}, SortKey{Column: 0, Numeric: true, Descending: true})
+	w.MaxRecords = 2
+	w.TempDir = dir
+
+	is.NoError(w.WriteHeader([]string{"id", "country"}))
+	for _, record := range [][]string{
+		{"9", "us"},
+		{"10", "fr"},
+		{"2", "fr"},
+		{"=1", "us"},
+		{"x\r\ny", "fr"},
+		{"10", "fr", "last"},
+		{"3"},
+	} {
+		is.NoError(w.Write(record))
+	}
+
+	entries, err := os.ReadDir(dir)
+	is.NoError(err)
+	is.Len(entries, 3)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,country\n3\n\"x\r\ny\",fr\n10,fr\n10,fr,last\n2,fr\n\" =1\",us\n9,us\n", buff.String())
+
+	entries, err = os.ReadDir(dir)
+	is.NoError(err)
+	is.Empty(entries)
+
+	w.TempDir = dir + "/missing"
+	is.NoError(w.Write([]string{"a"}))
+	is.Error(w.Write([]string{"b"}))
+	is.Error(w.Write([]string{"c"}))
+	w.Flush()
+	is.Error(w.Error())
+}
+
+func TestSortingWriterNumericOrder(t *testing.T) {
+	is := assert.New(t)
+
+	for _, input := range [][]string{
+		{"10", "9a", "9", "", "NaN", "-1e3"},
+		{"9", "NaN", "10", "9a", "-1e3", ""},
+		{"9a", "", "9", "-1e3", "10", "NaN"},
+	} {
+		var buff strings.Builder
+		w := NewSortingWriter(NewSafeWriter(&buff, SafetyOpts{}), SortKey{Column: 0, Numeric: true})
+		for _, field := range input {
+			is.NoError(w.Write([]string{field, "x"}))
+		}
+		w.Flush()
+		is.NoError(w.Error())
+		is.Equal("-1e3,x\n9,x\n10,x\n,x\n9a,x\nNaN,x\n", buff.String(), input)
+	}
+}
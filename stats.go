@@ -0,0 +1,14 @@
+package csv
+
+// Stats reports counters about the records written by a [SafeWriter].
+type Stats struct {
+	Records         int64 // Records written, trusted or not
+	TrustedRecords  int64 // Records written without sanitization
+	SanitizedFields int64 // Fields altered by sanitization
+	RejectedRecords int64 // Records rejected by validation or sanitization
+}
+
+// Stats returns the counters of the records written so far.
+func (w *SafeWriter) Stats() Stats {
+	return w.stats
+}
@@ -0,0 +1,52 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.Equal(Stats{}, w.Stats())
+
+	is.NoError(w.Write([]string{"=A1", "-1", "ok"}))
+	is.NoError(w.WriteCells([]Cell{StringCell("@a"), Formula("=SUM(A1:A2)")}))
+	is.NoError(w.WriteAllTrusted([][]string{{"=B1"}, {"+1"}}))
+	is.Equal(Stats{Records: 4, TrustedRecords: 2, SanitizedFields: 3}, w.Stats())
+	is.Equal("\" =A1\",\" -1\",ok\n\" @a\",=SUM(A1:A2)\n=B1\n+1\n", buff.String())
+
+	w.Comma = '"'
+	is.Error(w.WriteRecordUnsafe([]string{"a"}))
+	is.EqualValues(2, w.Stats().TrustedRecords)
+}
+
+func TestMaxErrors(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.MaxErrors = 2
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.NoError(w.Write([]string{"1"}))
+	is.ErrorIs(w.Write([]string{""}), ErrRequiredField)
+	is.ErrorIs(w.WriteCells([]Cell{StringCell("")}), ErrRequiredField)
+	is.NoError(w.Write([]string{"2"}))
+
+	err := w.Write([]string{""})
+	is.ErrorIs(err, ErrTooManyErrors)
+	is.EqualError(err, `csv: too many rejected records (3 > 2), last: csv: record 2, column 0: required field is empty`)
+	is.ErrorIs(w.Write([]string{"3"}), ErrTooManyErrors)
+	is.ErrorIs(w.WriteCells([]Cell{StringCell("3")}), ErrTooManyErrors)
+	is.ErrorIs(w.WriteTrailer([]string{"TRL"}), ErrTooManyErrors)
+	is.ErrorIs(w.Close(), ErrTooManyErrors)
+	is.ErrorIs(w.Error(), ErrTooManyErrors)
+	is.Equal("1\n2\n", buff.String())
+	is.Equal(Stats{Records: 2, RejectedRecords: 3}, w.Stats())
+}
@@ -0,0 +1,486 @@
+//go:build !tinygo && !safecsv_noreflect
+// +build !tinygo,!safecsv_noreflect
+
+package csv
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidStruct        = errors.New("csv: WriteStruct expects a struct or a non-nil pointer to a struct")
+	errInvalidListSeparator = errors.New("list separator conflicts with the field delimiter")
+)
+
+func init() {
+	features = append(features, FeatureStructs)
+}
+
+// structCache holds the fields of the struct types written so far.
+type structCache map[reflect.Type][]structField
+
+// structField is an exported field of a struct written by
+// [SafeWriter.WriteStruct].
+type structField struct {
+	name    string
+	index   []int
+	order   int
+	ordered bool // True when the field has an order option
+	named   bool // True when the tag names the field
+	typ     reflect.Type
+	embed   bool
+	hidden  bool // True for an embedded struct of an unexported type
+}
+
+// numberCell is the text of a finite numeric struct field. Like
+// [NumberCell], it is never escaped.
+type numberCell string
+
+func (c numberCell) Value() string      { return string(c) }
+func (c numberCell) Policy() CellPolicy { return CellPolicy{Trusted: true} }
+
+// WriteStruct writes v, a struct or a pointer to a struct, as a single CSV
+// record. A header must have been written first, unless
+// [SafeWriter.AutoHeader] is set: fields are matched with the header by name,
+// and header names without a matching field are written with the default
+// value of their column.
+//
+// The name of a field is given by its `csv` tag, and defaults to the name of
+// the field. Fields tagged `csv:"-"` and unexported fields are ignored. The
+// order option of the tag, such as `csv:"name,order=3"`, sets the position
+// of the field in [StructHeader]. Nested structs are written according to
+// [SafeWriter.Flatten].
+//
+// Values of the types registered with RegisterTypeMarshaler are rendered by
+// their marshaler. Strings, [encoding.TextMarshaler] and [fmt.Stringer]
+// values are sanitized, numbers are written as is, booleans are rendered
+// like a [BoolCell] and [Cell] values keep their own policy. [driver.Valuer] values, such as
+// sql.NullString or mo.Option, are written as the value they return. Nil
+// pointers and nil values are written according to [ColumnOpts.Null], and
+// slices and maps according to [ColumnOpts.List].
+func (w *SafeWriter) WriteStruct(v interface{}) error {
+	if w.input == nil {
+		if !w.AutoHeader {
+			return errMissingHeader
+		}
+
+		header, err := w.Flatten.StructHeader(v)
+		if err != nil {
+			return err
+		}
+		if err := w.WriteHeader(header); err != nil {
+			return err
+		}
+	}
+
+	rv, err := structValue(v)
+	if err != nil {
+		return err
+	}
+
+	if w.structs == nil {
+		w.structs = structCache{}
+	}
+	fields, ok := w.structs[rv.Type()]
+	if !ok {
+		if fields, err = parseStructFields(rv.Type(), w.Flatten); err != nil {
+			return err
+		}
+		w.structs[rv.Type()] = fields
+	}
+
+	values := make(map[string]structField, len(fields))
+	for _, field := range fields {
+		name := w.HeaderCase.normalize(field.name)
+		if err := w.checkInputName(name); err != nil {
+			return err
+		}
+		values[name] = field
+	}
+
+	cells := make([]Cell, len(w.input))
+	for i, name := range w.input {
+		field, ok := values[name]
+		if !ok {
+			cells[i] = w.missingField(i)
+			continue
+		}
+
+		cell, err := w.structFieldCell(fieldByIndex(rv, field.index), w.inputColumn(i))
+		if err != nil {
+			return fmt.Errorf("csv: field %s: %w", field.name, err)
+		}
+		if cell == nil {
+			cell = w.nullField(i)
+		}
+		cells[i] = cell
+	}
+	return w.WriteCells(cells)
+}
+
+// structFieldCell converts the value of a struct field written to the
+// column col into a cell. It returns a nil cell for null values.
+func (w *SafeWriter) structFieldCell(v reflect.Value, col ColumnOpts) (Cell, error) {
+	if col.List == ListDefault {
+		return structCell(v)
+	}
+
+	for v.Kind() == reflect.Ptr && !v.IsNil() && !hasCellMethods(v.Type()) {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if hasCellMethods(v.Type()) {
+			break
+		}
+		if v.Kind() != reflect.Array && v.IsNil() {
+			return nil, nil
+		}
+		if col.List == ListJSON {
+			b, err := json.Marshal(v.Interface())
+			if err != nil {
+				return nil, err
+			}
+			return StringCell(b), nil
+		}
+		return w.joinList(v, col)
+	}
+	return structCell(v)
+}
+
+// joinList joins the elements of a slice, an array or a map with the list
+// separator of col. Map entries are written as key=value, sorted by key.
+func (w *SafeWriter) joinList(v reflect.Value, col ColumnOpts) (Cell, error) {
+	sep := col.listSeparator()
+	if strings.ContainsRune(sep, w.Comma) || strings.ContainsAny(sep, "\"\r\n") {
+		return nil, errInvalidListSeparator
+	}
+
+	text := func(v reflect.Value) (string, error) {
+		cell, err := structCell(v)
+		if err != nil || cell == nil {
+			return "", err
+		}
+		value := cell.Value()
+		if strings.Contains(value, sep) {
+			return "", ErrListElement
+		}
+		return value, nil
+	}
+
+	var items []string
+	if v.Kind() == reflect.Map {
+		for _, key := range v.MapKeys() {
+			k, err := text(key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := text(v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, k+"="+value)
+		}
+		sort.Strings(items)
+	} else {
+		for i := 0; i < v.Len(); i++ {
+			item, err := text(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+	return StringCell(strings.Join(items, sep)), nil
+}
+
+// hasCellMethods reports whether the values of type t are written by a
+// registered marshaler, or through one of their methods, as a [Cell], a
+// [driver.Valuer], an [encoding.TextMarshaler] or a [fmt.Stringer].
+func hasCellMethods(t reflect.Type) bool {
+	if _, ok := lookupMarshaler(reflect.Zero(t).Interface()); ok {
+		return true
+	}
+	return t.Implements(cellType) || t.Implements(valuerType) || t.Implements(textMarshalerType) || t.Implements(stringerType)
+}
+
+// StructHeader returns the column names of v, a struct or a pointer to a
+// struct, without flattening nested structs. See [SafeWriter.WriteStruct]
+// and [FlattenOpts.StructHeader].
+func StructHeader(v interface{}) ([]string, error) {
+	return FlattenOpts{}.StructHeader(v)
+}
+
+// OrderedStructHeader returns the column names of v like [StructHeader]
+// does, with the names of order first, in that order.
+func OrderedStructHeader(v interface{}, order []string) ([]string, error) {
+	return FlattenOpts{}.OrderedStructHeader(v, order)
+}
+
+// StructHeader returns the column names of v, a struct or a pointer to a
+// struct, with nested structs flattened according to opts. See
+// [SafeWriter.WriteStruct].
+//
+// Fields with an order option come first, by increasing order, followed by
+// the other fields in the order of their declaration, so that the columns
+// of an export do not move when fields are reordered. The fields of nested
+// structs take the position of their parent.
+func (opts FlattenOpts) StructHeader(v interface{}) ([]string, error) {
+	return opts.OrderedStructHeader(v, nil)
+}
+
+// OrderedStructHeader returns the column names of v like
+// [FlattenOpts.StructHeader] does, with the names of order first, in that
+// order. Names missing from v are reported as [ErrUnknownColumn].
+func (opts FlattenOpts) OrderedStructHeader(v interface{}, order []string) ([]string, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := parseStructFields(rv.Type(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]string, 0, len(fields))
+	for _, name := range order {
+		found := false
+		for _, field := range fields {
+			if field.name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+		header = append(header, name)
+	}
+
+	for _, field := range fields {
+		if !contains(order, field.name) {
+			header = append(header, field.name)
+		}
+	}
+	return header, nil
+}
+
+// structValue dereferences v down to a struct.
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, errInvalidStruct
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, errInvalidStruct
+	}
+	return rv, nil
+}
+
+// parseStructFields lists the exported fields of t, with nested structs
+// flattened according to opts, in the order of [StructHeader].
+func parseStructFields(t reflect.Type, opts FlattenOpts) ([]structField, error) {
+	return opts.appendFields(nil, t, "", nil, 0, map[reflect.Type]bool{})
+}
+
+// appendFields appends the fields of t, nested at the given depth, to
+// fields. Their names are prefixed by prefix, and their indexes by index.
+// parents holds the struct types being flattened, to detect cycles when the
+// depth is unbounded.
+func (opts FlattenOpts) appendFields(fields []structField, t reflect.Type, prefix string, index []int, depth int, parents map[reflect.Type]bool) ([]structField, error) {
+	level, err := levelFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	parents[t] = true
+	defer delete(parents, t)
+
+	for _, field := range level {
+		field.name = prefix + field.name
+		field.index = append(append([]int{}, index...), field.index...)
+
+		nested, ok := flattenedType(field.typ)
+		if !ok || opts.MaxDepth >= 0 && depth >= opts.MaxDepth {
+			if !field.hidden {
+				fields = append(fields, field)
+			}
+			continue
+		}
+		// Recursion is bounded by MaxDepth, unless it is negative.
+		if opts.MaxDepth < 0 && parents[nested] {
+			return nil, fmt.Errorf("csv: field %s: recursive struct %s", field.name, nested)
+		}
+
+		nestedPrefix := field.name + opts.separator()
+		if field.embed && !field.named && !opts.PrefixEmbedded {
+			nestedPrefix = prefix
+		}
+		if fields, err = opts.appendFields(fields, nested, nestedPrefix, field.index, depth+1, parents); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// levelFields lists the exported fields of t, without flattening, sorted by
+// their order option. Embedded structs of unexported types are listed too,
+// since their exported fields are promoted.
+func levelFields(t reflect.Type) ([]structField, error) {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		hidden := f.PkgPath != ""
+		if hidden && !f.Anonymous {
+			continue
+		}
+
+		field := structField{name: f.Name, index: f.Index, typ: f.Type, embed: f.Anonymous, hidden: hidden}
+		if tag, ok := f.Tag.Lookup("csv"); ok {
+			if tag == "-" {
+				continue
+			}
+
+			options := strings.Split(tag, ",")
+			if options[0] != "" {
+				field.name, field.named = options[0], true
+			}
+			for _, option := range options[1:] {
+				if !strings.HasPrefix(option, "order=") {
+					continue
+				}
+				order, err := strconv.Atoi(strings.TrimPrefix(option, "order="))
+				if err != nil {
+					return nil, fmt.Errorf("csv: field %s: invalid %q option", f.Name, option)
+				}
+				field.order, field.ordered = order, true
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if a.ordered != b.ordered {
+			return a.ordered
+		}
+		return a.ordered && a.order < b.order
+	})
+	return fields, nil
+}
+
+// flattenedType returns the struct type of the fields of type t that are
+// flattened: structs and pointers to structs written neither as a [Cell],
+// a [driver.Valuer], an [encoding.TextMarshaler] nor a [fmt.Stringer].
+func flattenedType(t reflect.Type) (reflect.Type, bool) {
+	for {
+		if hasCellMethods(t) {
+			return nil, false
+		}
+		if t.Kind() != reflect.Ptr {
+			break
+		}
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// fieldByIndex returns the nested field of v at index, dereferencing
+// pointers to structs. It returns the first nil pointer met, if any.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, n := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return v
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(n)
+	}
+	return v
+}
+
+var (
+	cellType          = reflect.TypeOf((*Cell)(nil)).Elem()
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// structCell converts the value of a struct field into a cell. It returns a
+// nil cell for null values.
+func structCell(v reflect.Value) (Cell, error) {
+	for {
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			return nil, nil
+		}
+
+		if v.CanInterface() {
+			if text, ok, err := marshalRegistered(v.Interface()); ok {
+				return StringCell(text), err
+			}
+		}
+
+		switch {
+		case v.Type().Implements(cellType):
+			return v.Interface().(Cell), nil
+		case v.Type().Implements(valuerType):
+			value, err := v.Interface().(driver.Valuer).Value()
+			if err != nil || value == nil {
+				return nil, err
+			}
+			if b, ok := value.([]byte); ok {
+				return StringCell(b), nil
+			}
+			return structCell(reflect.ValueOf(value))
+		case v.Type().Implements(textMarshalerType):
+			text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			return StringCell(text), nil
+		case v.Type().Implements(stringerType):
+			return StringCell(v.Interface().(fmt.Stringer).String()), nil
+		}
+
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return StringCell(v.String()), nil
+	case reflect.Bool:
+		return BoolCell(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return numberCell(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return numberCell(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		text := strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+		if !isFinite(v.Float()) {
+			return StringCell(text), nil // +Inf, -Inf and NaN are sanitized
+		}
+		return numberCell(text), nil
+	default:
+		return StringCell(fmt.Sprint(v.Interface())), nil
+	}
+}
@@ -0,0 +1,45 @@
+//go:build tinygo || safecsv_noreflect
+// +build tinygo safecsv_noreflect
+
+package csv
+
+import "errors"
+
+// This file replaces struct.go in builds without reflection, such as TinyGo
+// and WebAssembly targets, to keep the core writer small. Use the
+// safecsv_noreflect build tag to select it with the regular toolchain.
+
+var errNoReflection = errors.New("csv: structs are not supported in builds without reflection")
+
+// structCache is unused without reflection.
+type structCache struct{}
+
+// WriteStruct is not supported in builds without reflection, and always
+// returns an error. Use [SafeWriter.WriteMap] instead.
+func (w *SafeWriter) WriteStruct(v interface{}) error {
+	return errNoReflection
+}
+
+// StructHeader is not supported in builds without reflection, and always
+// returns an error.
+func StructHeader(v interface{}) ([]string, error) {
+	return nil, errNoReflection
+}
+
+// OrderedStructHeader is not supported in builds without reflection, and
+// always returns an error.
+func OrderedStructHeader(v interface{}, order []string) ([]string, error) {
+	return nil, errNoReflection
+}
+
+// StructHeader is not supported in builds without reflection, and always
+// returns an error.
+func (opts FlattenOpts) StructHeader(v interface{}) ([]string, error) {
+	return nil, errNoReflection
+}
+
+// OrderedStructHeader is not supported in builds without reflection, and
+// always returns an error.
+func (opts FlattenOpts) OrderedStructHeader(v interface{}, order []string) ([]string, error) {
+	return nil, errNoReflection
+}
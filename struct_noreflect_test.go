@@ -0,0 +1,31 @@
+//go:build tinygo || safecsv_noreflect
+// +build tinygo safecsv_noreflect
+
+package csv
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteStructNoReflection(t *testing.T) {
+	is := assert.New(t)
+
+	w := NewSafeWriter(io.Discard, EscapeAll)
+	is.NoError(w.WriteHeader([]string{"id"}))
+	is.ErrorIs(w.WriteStruct(struct{ ID int }{ID: 1}), errNoReflection)
+
+	_, err := StructHeader(struct{ ID int }{})
+	is.ErrorIs(err, errNoReflection)
+	_, err = OrderedStructHeader(struct{ ID int }{}, nil)
+	is.ErrorIs(err, errNoReflection)
+
+	is.False(HasFeature(FeatureStructs))
+	is.False(HasFeature(FeatureExpvar))
+	is.False(HasFeature(FeatureProfileLabels))
+
+	w.PublishExpvar("test_noreflect")
+	is.Nil(w.vars)
+}
@@ -0,0 +1,421 @@
+//go:build !tinygo && !safecsv_noreflect
+// +build !tinygo,!safecsv_noreflect
+
+package csv
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID        int       `csv:"id"`
+	Name      string    `csv:"name"`
+	Balance   float64   `csv:"balance"`
+	Admin     bool      `csv:"admin"`
+	CreatedAt time.Time `csv:"created_at"`
+	Manager   *string   `csv:"manager,omitempty"`
+	Total     Formula
+	Password  string `csv:"-"`
+	internal  string
+}
+
+func TestStructHeader(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(HasFeature(FeatureStructs))
+
+	header, err := StructHeader(&testUser{})
+	is.NoError(err)
+	is.Equal([]string{"id", "name", "balance", "admin", "created_at", "manager", "Total"}, header)
+
+	_, err = StructHeader(42)
+	is.ErrorIs(err, errInvalidStruct)
+}
+
+func TestStructHeaderOrder(t *testing.T) {
+	is := assert.New(t)
+
+	type account struct {
+		Name    string `csv:"name,order=2"`
+		Email   string `csv:"email"`
+		ID      int    `csv:"id,order=1"`
+		Country string
+		Balance int `csv:",order=2"`
+	}
+
+	header, err := StructHeader(account{})
+	is.NoError(err)
+	is.Equal([]string{"id", "name", "Balance", "email", "Country"}, header)
+
+	header, err = OrderedStructHeader(account{}, []string{"email", "Country"})
+	is.NoError(err)
+	is.Equal([]string{"email", "Country", "id", "name", "Balance"}, header)
+
+	_, err = OrderedStructHeader(account{}, []string{"missing"})
+	is.ErrorIs(err, ErrUnknownColumn)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.NoError(w.WriteHeader(header))
+	is.NoError(w.WriteStruct(account{Name: "alice", Email: "a@example.com", ID: 1, Country: "fr", Balance: 42}))
+	w.Flush()
+	is.Equal("email,Country,id,name,Balance\na@example.com,fr,1,alice,42\n", buff.String())
+
+	type invalid struct {
+		ID int `csv:"id,order=first"`
+	}
+	_, err = StructHeader(invalid{})
+	is.EqualError(err, `csv: field ID: invalid "order=first" option`)
+	is.EqualError(w.WriteStruct(invalid{}), `csv: field ID: invalid "order=first" option`)
+}
+
+type testAudit struct {
+	CreatedAt time.Time `csv:"created_at"`
+	Author    *testAuthor
+}
+
+type testAuthor struct {
+	Name    string `csv:"name"`
+	Contact struct {
+		Email string `csv:"email"`
+	} `csv:"contact"`
+}
+
+type testOrder struct {
+	testAudit
+	ID int `csv:"id,order=1"`
+}
+
+type testNode struct {
+	Name string `csv:"name"`
+	Next *testNode
+}
+
+func TestWriteStructFlatten(t *testing.T) {
+	is := assert.New(t)
+
+	header, err := StructHeader(testOrder{})
+	is.NoError(err)
+	is.Equal([]string{"id"}, header)
+
+	opts := FlattenOpts{MaxDepth: 2}
+	header, err = opts.StructHeader(testOrder{})
+	is.NoError(err)
+	is.Equal([]string{"id", "created_at", "Author.name", "Author.contact"}, header)
+
+	opts = FlattenOpts{MaxDepth: 3, Separator: "_", PrefixEmbedded: true}
+	header, err = opts.StructHeader(&testOrder{})
+	is.NoError(err)
+	is.Equal([]string{"id", "testAudit_created_at", "testAudit_Author_name", "testAudit_Author_contact_email"}, header)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Flatten = opts
+	is.NoError(w.WriteHeader(header))
+
+	order := testOrder{ID: 1}
+	order.CreatedAt = time.Date(2024, 12, 5, 0, 0, 0, 0, time.UTC)
+	is.NoError(w.WriteStruct(order))
+	order.Author = &testAuthor{Name: "=alice"}
+	order.Author.Contact.Email = "a@example.com"
+	is.NoError(w.WriteStruct(&order))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,testAudit_created_at,testAudit_Author_name,testAudit_Author_contact_email\n"+
+		"1,2024-12-05T00:00:00Z,,\n"+
+		"1,2024-12-05T00:00:00Z,\" =alice\",a@example.com\n", buff.String())
+
+	header, err = FlattenOpts{MaxDepth: 2}.StructHeader(testNode{})
+	is.NoError(err)
+	is.Equal([]string{"name", "Next.name", "Next.Next.name", "Next.Next.Next"}, header)
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Flatten = FlattenOpts{MaxDepth: 2}
+	is.NoError(w.WriteHeader(header))
+	is.NoError(w.WriteStruct(testNode{Name: "a", Next: &testNode{Name: "=b"}}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("name,Next.name,Next.Next.name,Next.Next.Next\na,\" =b\",,\n", buff.String())
+
+	_, err = FlattenOpts{MaxDepth: -1}.StructHeader(testNode{})
+	is.EqualError(err, "csv: field Next: recursive struct csv.testNode")
+
+	header, err = FlattenOpts{MaxDepth: -1}.StructHeader(testOrder{})
+	is.NoError(err)
+	is.Equal([]string{"id", "created_at", "Author.name", "Author.contact.email"}, header)
+
+	header, err = FlattenOpts{MaxDepth: 5}.OrderedStructHeader(testAuthor{}, []string{"contact.email"})
+	is.NoError(err)
+	is.Equal([]string{"contact.email", "name"}, header)
+}
+
+func TestWriteStructNull(t *testing.T) {
+	is := assert.New(t)
+
+	type row struct {
+		Manager *string        `csv:"manager"`
+		Email   sql.NullString `csv:"email"`
+		Age     sql.NullInt64  `csv:"age"`
+		Score   sql.NullFloat64
+		Nested  *struct{ ID int }
+	}
+
+	opts := FlattenOpts{MaxDepth: 1}
+	header, err := opts.StructHeader(row{})
+	is.NoError(err)
+	is.Equal([]string{"manager", "email", "age", "Score", "Nested.ID"}, header)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Flatten = opts
+	w.Columns = []ColumnOpts{
+		{Null: NullAsValue, NullValue: "NULL"},
+		{Null: NullAsDefault, Default: "unknown"},
+		{Null: NullAsValue, NullValue: "=NULL"},
+		{},
+		{Null: NullAsValue, NullValue: `\N`},
+	}
+	is.NoError(w.WriteHeader(header))
+
+	manager := "bob"
+	is.NoError(w.WriteStruct(row{}))
+	is.NoError(w.WriteStruct(row{
+		Manager: &manager,
+		Email:   sql.NullString{String: "=a@example.com", Valid: true},
+		Age:     sql.NullInt64{Int64: -42, Valid: true},
+		Score:   sql.NullFloat64{Float64: 1.5, Valid: true},
+		Nested:  &struct{ ID int }{ID: 7},
+	}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("manager,email,age,Score,Nested.ID\nNULL,unknown,\" =NULL\",,\\N\nbob,\" =a@example.com\",-42,1.5,7\n", buff.String())
+}
+
+func TestWriteStructList(t *testing.T) {
+	is := assert.New(t)
+
+	type row struct {
+		Tags   []string          `csv:"tags"`
+		Scores *[2]int           `csv:"scores"`
+		Labels map[string]string `csv:"labels"`
+		Raw    []int             `csv:"raw"`
+	}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Comma = ';'
+	w.Columns = []ColumnOpts{
+		{List: ListJoined},
+		{List: ListJoined, ListSeparator: "/"},
+		{List: ListJSON},
+	}
+	is.NoError(w.WriteHeader([]string{"tags", "scores", "labels", "raw"}))
+	is.NoError(w.WriteStruct(row{
+		Tags:   []string{"=a", "b"},
+		Scores: &[2]int{1, -2},
+		Labels: map[string]string{"z": "1", "a": "=2"},
+		Raw:    []int{1, 2},
+	}))
+	is.NoError(w.WriteStruct(row{}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("tags;scores;labels;raw\n\" =a|b\";1/-2;\"{\"\"a\"\":\"\"=2\"\",\"\"z\"\":\"\"1\"\"}\";[1 2]\n;;;[]\n", buff.String())
+
+	err := w.WriteStruct(row{Tags: []string{"a|b"}})
+	is.ErrorIs(err, ErrListElement)
+	is.EqualError(err, "csv: field tags: list element contains the separator")
+
+	w.Columns[0].ListSeparator = ";"
+	is.EqualError(w.WriteStruct(row{Tags: []string{"a"}}), "csv: field tags: list separator conflicts with the field delimiter")
+}
+
+func TestWriteStruct(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	manager := "=boss"
+	user := testUser{
+		ID:        -1,
+		Name:      "@alice",
+		Balance:   -12.5,
+		Admin:     true,
+		CreatedAt: time.Date(2024, 12, 5, 0, 0, 0, 0, time.UTC),
+		Manager:   &manager,
+		Total:     "=SUM(A1:A2)",
+		Password:  "secret",
+		internal:  "internal",
+	}
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.BoolFormat = BoolYN
+	is.ErrorIs(w.WriteStruct(user), errMissingHeader)
+
+	header, err := StructHeader(user)
+	is.NoError(err)
+	is.NoError(w.WriteHeader(append(header, "missing")))
+	is.NoError(w.WriteStruct(user))
+	is.NoError(w.WriteStruct(&testUser{ID: 2}))
+	is.ErrorIs(w.WriteStruct((*testUser)(nil)), errInvalidStruct)
+	is.ErrorIs(w.WriteStruct("user"), errInvalidStruct)
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal(`id,name,balance,admin,created_at,manager,Total,missing
+-1," @alice",-12.5,Y,2024-12-05T00:00:00Z," =boss",=SUM(A1:A2),
+2,,0,N,0001-01-01T00:00:00Z,,,
+`, buff.String())
+}
+
+func TestWriteStructAutoHeader(t *testing.T) {
+	is := assert.New(t)
+
+	type item struct {
+		SKU   string `csv:"sku"`
+		Price int    `csv:"price,order=1"`
+	}
+	type other struct {
+		SKU  string `csv:"sku"`
+		Note string `csv:"note"`
+	}
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.AutoHeader = true
+	is.NoError(w.WriteStruct(item{SKU: "=A1", Price: 3}))
+	is.NoError(w.WriteStruct(&item{SKU: "b"}))
+	is.EqualError(w.WriteStruct(other{SKU: "c"}), `csv: unknown column: "note"`)
+	is.ErrorIs(w.WriteStruct(other{}), ErrUnknownColumn)
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("price,sku\n3,\" =A1\"\n0,b\n", buff.String())
+
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.AutoHeader = true
+	is.ErrorIs(w.WriteStruct(42), errInvalidStruct)
+	is.Nil(w.input)
+}
+
+func TestWriteStructNonFinite(t *testing.T) {
+	is := assert.New(t)
+
+	type measure struct {
+		A float64 `csv:"a"`
+		B float32 `csv:"b"`
+		C float64 `csv:"c"`
+		D float64 `csv:"d"`
+	}
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.AutoHeader = true
+	is.NoError(w.WriteStruct(measure{A: math.Inf(1), B: float32(math.Inf(-1)), C: math.NaN(), D: -1.5}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("a,b,c,d\n\" +Inf\",\" -Inf\",NaN,-1.5\n", buff.String())
+}
+
+func TestWriteStructLongNumber(t *testing.T) {
+	is := assert.New(t)
+
+	type card struct {
+		Number uint64 `csv:"number"`
+		CVC    int    `csv:"cvc"`
+	}
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, SafetyOpts{LongNumberDigits: 12})
+	w.AutoHeader = true
+	is.NoError(w.WriteStruct(card{Number: 4111111111111111, CVC: 123}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("number,cvc\n\"\t4111111111111111\",123\n", buff.String())
+}
+
+func TestSelectColumns(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.SelectColumns("name", "id")
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.NoError(w.WriteHeader([]string{"id", "email", "name"}))
+	is.NoError(w.Write([]string{"1", "a@b.c", "=alice"}))
+	is.NoError(w.WriteCells([]Cell{NumberCell(2), StringCell("b@c.d"), StringCell("bob")}))
+	is.NoError(w.WriteMap(map[string]string{"id": "3", "name": "carol", "other": "x"}))
+	is.NoError(w.WriteStruct(struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}{ID: 4, Name: "dave"}))
+
+	err := w.Write([]string{"5", "e@f.g"})
+	is.EqualError(err, `csv: record 5, column 0 ("name"): required field is empty`)
+
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("name,id\n\" =alice\",1\nbob,2\ncarol,3\ndave,4\n", buff.String())
+}
+
+func TestSelectColumnsErrors(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.ErrorIs(w.WriteMap(map[string]string{}), errMissingHeader)
+	is.ErrorIs(w.WriteHeader([]string{"id", "id"}), ErrDuplicateColumn)
+
+	w.SelectColumns("name")
+	err := w.WriteHeader([]string{"id"})
+	is.ErrorIs(err, ErrUnknownColumn)
+	is.EqualError(err, `csv: unknown column: "name"`)
+
+	w.Flush()
+	is.Empty(buff.String())
+}
+
+func TestWriteStructColumnDefault(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.SelectColumns("country", "id", "status")
+	w.Columns = []ColumnOpts{{Default: "FR"}, {}, {Default: "-"}}
+	is.NoError(w.WriteHeader([]string{"id", "status", "country", "note"}))
+	is.NoError(w.WriteStruct(struct {
+		ID int `csv:"id"`
+	}{ID: 3}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("country,id,status\nFR,3,\" -\"\n", buff.String())
+}
+
+func TestWriteStructHeaderCase(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.HeaderCase = HeaderLowerSnake
+	w.SelectColumns("UserID", "Email")
+	w.RenameColumns(map[string]string{"email": "Mail"})
+	is.NoError(w.WriteHeader([]string{"User ID", "Email", "Name"}))
+	is.NoError(w.WriteStruct(struct {
+		UserID int
+		Email  string
+	}{UserID: 2, Email: "b@c.d"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("user_id,Mail\n2,b@c.d\n", buff.String())
+}
@@ -0,0 +1,75 @@
+package csv
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// An ExportSummary reports what a [SafeWriter] wrote. It is the JSON
+// document written to [SafeWriter.Summary] by Close, standardizing the
+// report that export pipelines publish next to their files.
+type ExportSummary struct {
+	Columns []string       `json:"columns"` // Header written, after projection
+	Dialect SummaryDialect `json:"dialect"`
+
+	Records         int64 `json:"records"` // Header and trailer excluded
+	TrustedRecords  int64 `json:"trusted_records"`
+	SanitizedFields int64 `json:"sanitized_fields"`
+	RejectedRecords int64 `json:"rejected_records"`
+
+	Bytes    int64  `json:"bytes"`    // Bytes written, envelope included
+	Checksum string `json:"checksum"` // Hex-encoded SHA-256 of these bytes
+
+	Started  time.Time     `json:"started"` // Time of the first line, zero if none
+	Duration time.Duration `json:"duration_ns"`
+
+	Error    string            `json:"error,omitempty"` // Error of the writer, if any
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// SummaryDialect describes the CSV dialect of an [ExportSummary].
+type SummaryDialect struct {
+	Comma        string `json:"comma"`
+	CRLF         bool   `json:"crlf"`
+	Canonical    bool   `json:"canonical"`
+	ForceQuoting bool   `json:"force_quoting"`
+}
+
+// summary returns the summary of the output written so far, failed with
+// err if not nil.
+func (w *SafeWriter) summary(err error) ExportSummary {
+	info := w.trailerInfo()
+	summary := ExportSummary{
+		Columns: append([]string{}, w.header...),
+		Dialect: SummaryDialect{
+			Comma:        string(w.Comma),
+			CRLF:         w.UseCRLF && !w.Canonical,
+			Canonical:    w.Canonical,
+			ForceQuoting: w.opts.ForceDoubleQuotes && !w.Canonical,
+		},
+		Records:         info.Records,
+		TrustedRecords:  w.stats.TrustedRecords,
+		SanitizedFields: w.stats.SanitizedFields,
+		RejectedRecords: w.stats.RejectedRecords,
+		Bytes:           w.digested,
+		Checksum:        info.Checksum,
+		Started:         w.started,
+		Metadata:        w.Metadata,
+	}
+	if !w.started.IsZero() {
+		summary.Duration = time.Since(w.started)
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	return summary
+}
+
+// writeSummary writes the summary of the output to out, as a single line of
+// JSON.
+func (w *SafeWriter) writeSummary(out io.Writer, err error) error {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(w.summary(err))
+}
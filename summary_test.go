@@ -0,0 +1,64 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	is := assert.New(t)
+
+	var buff, report strings.Builder
+
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Comma = ';'
+	w.Summary = &report
+	w.Trailer = CountTrailer("TRL")
+	w.Metadata = map[string]string{"export": "42"}
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.NoError(w.WriteHeader([]string{"id", "cmd"}))
+	is.NoError(w.Write([]string{"1", "=A1"}))
+	is.NoError(w.WriteRecordUnsafe([]string{"2", "=SUM(A1)"}))
+	is.ErrorIs(w.Write([]string{"", "x"}), ErrRequiredField)
+	is.Empty(report.String())
+	is.NoError(w.Close())
+	is.NoError(w.Close())
+	is.Equal("id;cmd\n1;\" =A1\"\n2;=SUM(A1)\nTRL;2\n", buff.String())
+	is.Equal(1, strings.Count(report.String(), "\n"))
+
+	var summary ExportSummary
+	is.NoError(json.Unmarshal([]byte(report.String()), &summary))
+	checksum := sha256.Sum256([]byte(buff.String()))
+	is.False(summary.Started.IsZero())
+	is.True(summary.Duration >= 0)
+	summary.Started, summary.Duration = summary.Started.UTC(), 0
+	is.Equal(ExportSummary{
+		Columns:         []string{"id", "cmd"},
+		Dialect:         SummaryDialect{Comma: ";"},
+		Records:         2,
+		TrustedRecords:  1,
+		SanitizedFields: 1,
+		RejectedRecords: 1,
+		Bytes:           int64(buff.Len()),
+		Checksum:        hex.EncodeToString(checksum[:]),
+		Started:         summary.Started,
+		Metadata:        map[string]string{"export": "42"},
+	}, summary)
+
+	report.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Summary = &report
+	w.MaxErrors = 1
+	w.Columns = []ColumnOpts{{Required: true}}
+	is.Error(w.Write([]string{""}))
+	is.ErrorIs(w.Write([]string{""}), ErrTooManyErrors)
+	is.ErrorIs(w.Close(), ErrTooManyErrors)
+	is.Contains(report.String(), `"rejected_records":2,`)
+	is.Contains(report.String(), `"error":"csv: too many rejected records (2 > 1)`)
+	is.Contains(report.String(), `"started":"0001-01-01T00:00:00Z","duration_ns":0,`)
+}
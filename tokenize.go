@@ -0,0 +1,209 @@
+package csv
+
+import (
+	"context"
+	"sync"
+)
+
+// A TokenizeFunc returns the token replacing value, typically from an
+// external vault. It may be called concurrently.
+type TokenizeFunc func(ctx context.Context, value string) (token string, err error)
+
+// A TokenizingWriter replaces the values of some columns by tokens before
+// writing records to a [SafeWriter], so that PII can be exported as vault
+// tokens. Records are buffered and tokenized by batches: the distinct values
+// of a batch are tokenized once each, with concurrent calls.
+//
+// The exported fields can be changed before the first call to Write.
+type TokenizingWriter struct {
+	// BatchSize is the number of records buffered before they are
+	// tokenized and written (100 when 0).
+	BatchSize int
+	// Concurrency is the maximum number of concurrent calls of the
+	// tokenizers (8 when 0).
+	Concurrency int
+
+	ctx      context.Context
+	w        *SafeWriter
+	columns  map[int]TokenizeFunc
+	pending  [][]string
+	err      error // Error of the tokenizers, stopping the writer
+	flushErr error // Error of the records written by the last Flush
+}
+
+// NewTokenizingWriter returns a TokenizingWriter writing to w, tokenizing
+// the fields at the positions of columns in the records given to Write,
+// before projection, with functions called with ctx. Empty fields are not
+// tokenized.
+func NewTokenizingWriter(ctx context.Context, w *SafeWriter, columns map[int]TokenizeFunc) *TokenizingWriter {
+	return &TokenizingWriter{
+		ctx:     ctx,
+		w:       w,
+		columns: columns,
+	}
+}
+
+// WriteHeader writes the header with [SafeWriter.WriteHeader], without
+// tokenization. It must be called before the first record is written.
+func (t *TokenizingWriter) WriteHeader(header []string) error {
+	return t.w.WriteHeader(header)
+}
+
+// Write buffers a copy of record, then tokenizes and writes the buffered
+// records if the batch is full.
+//
+// Errors of the tokenizers are returned as a [ColumnError]: the records of
+// the batch are then dropped, and every later call fails. Records rejected
+// by the [SafeWriter] are skipped, and the first error is returned once the
+// other records of the batch are written.
+func (t *TokenizingWriter) Write(record []string) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	t.pending = append(t.pending, append([]string{}, record...))
+	if len(t.pending) < t.batchSize() {
+		return nil
+	}
+	return t.writeBatch()
+}
+
+// Flush tokenizes and writes the buffered records, then flushes the
+// [SafeWriter]. To check if an error occurred, call
+// [TokenizingWriter.Error].
+func (t *TokenizingWriter) Flush() {
+	if t.err == nil && len(t.pending) > 0 {
+		t.flushErr = t.writeBatch()
+	}
+	t.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Flush, or that
+// stopped the writer.
+func (t *TokenizingWriter) Error() error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.flushErr != nil {
+		return t.flushErr
+	}
+	return t.w.Error()
+}
+
+func (t *TokenizingWriter) batchSize() int {
+	if t.BatchSize <= 0 {
+		return 100
+	}
+	return t.BatchSize
+}
+
+// tokenKey identifies a value to tokenize within a batch.
+type tokenKey struct {
+	column int
+	value  string
+}
+
+// writeBatch tokenizes and writes the buffered records.
+func (t *TokenizingWriter) writeBatch() error {
+	records := t.pending
+	t.pending = nil
+
+	tokens, err := t.tokenize(records)
+	if err != nil {
+		t.err = err
+		return err
+	}
+
+	var first error
+	for _, record := range records {
+		for n, field := range record {
+			if _, ok := t.columns[n]; ok && field != "" {
+				record[n] = tokens[tokenKey{column: n, value: field}]
+			}
+		}
+		if err := t.w.Write(record); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// tokenize returns the tokens of the distinct non-empty values of records.
+func (t *TokenizingWriter) tokenize(records [][]string) (map[tokenKey]string, error) {
+	if err := t.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// rows holds the index of the first record holding each value, for
+	// errors.
+	rows := map[tokenKey]int{}
+	var keys []tokenKey
+	for i, record := range records {
+		for n, field := range record {
+			key := tokenKey{column: n, value: field}
+			if _, ok := t.columns[n]; !ok || field == "" {
+				continue
+			}
+			if _, ok := rows[key]; !ok {
+				rows[key] = i
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	concurrency := t.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	first := tokenKey{column: -1}
+	tokens := make(map[tokenKey]string, len(keys))
+	sem := make(chan struct{}, concurrency)
+
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key tokenKey) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			token, err := t.columns[key.column](t.ctx, key.value)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				// Report the error of the first field, in order.
+				if first.column < 0 || rows[key] < rows[first] || rows[key] == rows[first] && key.column < first.column {
+					first = key
+					firstErr = t.tokenError(rows[key], key, err)
+				}
+				return
+			}
+			tokens[key] = token
+		}(key)
+	}
+	wg.Wait()
+
+	return tokens, firstErr
+}
+
+// tokenError returns a [ColumnError] for the failure to tokenize key, in the
+// record at index row of the batch.
+func (t *TokenizingWriter) tokenError(row int, key tokenKey, err error) error {
+	var name string
+	if key.column < len(t.w.input) {
+		name = t.w.input[key.column]
+	}
+
+	return &ColumnError{
+		Row:      t.w.stats.Records + int64(row),
+		Column:   key.column,
+		Name:     name,
+		Value:    key.value,
+		Err:      err,
+		Metadata: t.w.Metadata,
+	}
+}
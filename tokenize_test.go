@@ -0,0 +1,93 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizingWriter(t *testing.T) {
+	is := assert.New(t)
+
+	var calls int32
+	vault := func(ctx context.Context, value string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "tok_" + strings.ToUpper(value), nil
+	}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	tw := NewTokenizingWriter(context.Background(), w, map[int]TokenizeFunc{1: vault})
+	tw.BatchSize = 3
+
+	is.NoError(tw.WriteHeader([]string{"id", "email"}))
+	is.NoError(tw.Write([]string{"1", "alice"}))
+	is.NoError(tw.Write([]string{"2", "bob"}))
+	is.Empty(strings.TrimPrefix(buff.String(), "id,email\n"))
+	is.NoError(tw.Write([]string{"3", "alice"}))
+	is.EqualValues(2, atomic.LoadInt32(&calls))
+
+	is.NoError(tw.Write([]string{"4", ""}))
+	is.NoError(tw.Write([]string{"=5", "carol"}))
+	tw.Flush()
+	is.NoError(tw.Error())
+	is.Equal("id,email\n1,tok_ALICE\n2,tok_BOB\n3,tok_ALICE\n4,\n\" =5\",tok_CAROL\n", buff.String())
+	is.EqualValues(3, atomic.LoadInt32(&calls))
+}
+
+func TestTokenizingWriterError(t *testing.T) {
+	is := assert.New(t)
+
+	errVault := errors.New("vault unavailable")
+	vault := func(ctx context.Context, value string) (string, error) {
+		if value == "bob" || value == "carol" {
+			return "", errVault
+		}
+		return "tok", nil
+	}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	tw := NewTokenizingWriter(context.Background(), w, map[int]TokenizeFunc{0: vault})
+	tw.Concurrency = 1
+	is.NoError(tw.WriteHeader([]string{"email"}))
+	is.NoError(tw.Write([]string{"alice"}))
+	is.NoError(tw.Write([]string{"carol"}))
+	is.NoError(tw.Write([]string{"bob"}))
+	tw.Flush()
+
+	err := tw.Error()
+	is.Equal(&ColumnError{Row: 2, Column: 0, Name: "email", Value: "carol", Err: errVault}, err)
+	is.Equal(err, tw.Write([]string{"dave"}))
+	is.Equal("email\n", buff.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tw = NewTokenizingWriter(ctx, NewSafeWriter(&buff, EscapeAll), map[int]TokenizeFunc{0: vault})
+	tw.BatchSize = 1
+	is.ErrorIs(tw.Write([]string{"alice"}), context.Canceled)
+}
+
+func TestTokenizingWriterRejected(t *testing.T) {
+	is := assert.New(t)
+
+	vault := func(ctx context.Context, value string) (string, error) { return "tok", nil }
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {Required: true}}
+	tw := NewTokenizingWriter(context.Background(), w, map[int]TokenizeFunc{0: vault})
+	tw.BatchSize = 3
+
+	is.NoError(tw.Write([]string{"a", "1"}))
+	is.NoError(tw.Write([]string{"b", ""}))
+	is.ErrorIs(tw.Write([]string{"c", "3"}), ErrRequiredField)
+	is.NoError(tw.Write([]string{"d", "4"}))
+	tw.Flush()
+	is.NoError(tw.Error())
+	is.Equal("tok,1\ntok,3\ntok,4\n", buff.String())
+}
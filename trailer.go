@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+var errRecordAfterTrailer = errors.New("csv: trailer must be the last record")
+
+// TrailerInfo summarizes the output of a [SafeWriter], to build its trailer.
+type TrailerInfo struct {
+	Records  int64  // Records written, header and trailer excluded
+	Bytes    int64  // Bytes written before the trailer, header included
+	Checksum string // Hex-encoded SHA-256 of these bytes
+}
+
+// CountTrailer returns a trailer holding label and the number of records,
+// such as TRAILER,42.
+func CountTrailer(label string) func(TrailerInfo) []string {
+	return func(info TrailerInfo) []string {
+		return []string{label, strconv.FormatInt(info.Records, 10)}
+	}
+}
+
+// ChecksumTrailer returns a trailer holding label, the number of records and
+// the checksum of the bytes written before the trailer.
+func ChecksumTrailer(label string) func(TrailerInfo) []string {
+	return func(info TrailerInfo) []string {
+		return []string{label, strconv.FormatInt(info.Records, 10), info.Checksum}
+	}
+}
+
+// WriteTrailer writes the trailer record, or control record, required by
+// many B2B file formats as the last line of a file. The trailer is sanitized
+// like the header, and no record can be written after it.
+func (w *SafeWriter) WriteTrailer(record []string) error {
+	if !validDelim(w.Comma) {
+		return errInvalidDelim
+	}
+
+	w.fields = w.fields[:0]
+	for n, field := range record {
+		prepared := preparedField{value: field, quote: w.quoteMode(QuoteAuto)}
+		escaped, err := w.opts.escape(field, n)
+		if err != nil {
+			return w.columnError(n, field, err)
+		}
+		if escaped != field {
+			prepared.value = escaped
+			prepared.sanitized = true
+		}
+		w.fields = append(w.fields, prepared)
+	}
+
+	if err := w.writeFields(); err != nil {
+		return err
+	}
+	w.trailed = true
+	return nil
+}
+
+// Close writes the trailer built by [SafeWriter.Trailer], if set and not
+// written yet, and the footer lines of [SafeWriter.Envelope], then flushes
+// the writer and returns any error. The [ExportSummary] is then written to
+// [SafeWriter.Summary], if set, once, including when the export failed. It
+// does not close the underlying [io.Writer].
+func (w *SafeWriter) Close() error {
+	err := w.close()
+	if w.Summary != nil && !w.summarized {
+		w.summarized = true
+		if serr := w.writeSummary(w.Summary, err); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// close writes the trailer and the footer, and flushes the writer.
+func (w *SafeWriter) close() error {
+	if w.Trailer != nil && !w.trailed {
+		if err := w.WriteTrailer(w.Trailer(w.trailerInfo())); err != nil {
+			return err
+		}
+	}
+	if w.Envelope != nil && !w.closed {
+		w.closed = true
+		if err := w.writeEnvelope(w.Envelope.Footer); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// trailerInfo returns the summary of the output written so far.
+func (w *SafeWriter) trailerInfo() TrailerInfo {
+	info := TrailerInfo{Records: w.stats.Records - w.headers, Bytes: w.digested}
+	if w.trailed {
+		info.Records--
+	}
+	if w.digest != nil {
+		info.Checksum = hex.EncodeToString(w.digest.Sum(nil))
+	} else {
+		info.Checksum = hex.EncodeToString(sha256.New().Sum(nil))
+	}
+	return info
+}
+
+// digestBuffer adds the content of the scratch buffer to the checksum of the
+// output, when a trailer, an envelope or a summary is to be built.
+func (w *SafeWriter) digestBuffer(n int) {
+	if w.Trailer == nil && w.Envelope == nil && w.Summary == nil {
+		return
+	}
+	if w.digest == nil {
+		w.digest = sha256.New()
+	}
+	_, _ = w.digest.Write(w.buf[:n])
+	w.digested += int64(n)
+}
@@ -0,0 +1,73 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTrailer(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	is.NoError(w.WriteHeader([]string{"id"}))
+	is.NoError(w.Write([]string{"1"}))
+	is.NoError(w.WriteTrailer([]string{"TRL", "=1"}))
+	is.ErrorIs(w.Write([]string{"2"}), errRecordAfterTrailer)
+	is.ErrorIs(w.WriteTrailer([]string{"TRL"}), errRecordAfterTrailer)
+	is.NoError(w.Close())
+	is.Equal("id\n1\nTRL,\" =1\"\n", buff.String())
+	is.Equal(Stats{Records: 3, SanitizedFields: 1}, w.Stats())
+}
+
+func TestAutoTrailer(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Trailer = ChecksumTrailer("TRAILER")
+	is.NoError(w.WriteHeader([]string{"id", "name"}))
+	is.NoError(w.WriteAll([][]string{{"1", "a"}, {"2", "b"}}))
+	is.NoError(w.Write([]string{"3", "c"}))
+	is.NoError(w.Close())
+
+	data := "id,name\n1,a\n2,b\n3,c\n"
+	sum := sha256.Sum256([]byte(data))
+	is.Equal(data+"TRAILER,3,"+hex.EncodeToString(sum[:])+"\n", buff.String())
+
+	// Close writes the trailer once.
+	is.NoError(w.Close())
+
+	buff.Reset()
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Trailer = CountTrailer("T")
+	is.NoError(w.Close())
+	is.Equal("T,0\n", buff.String())
+
+	var info TrailerInfo
+	w = NewSafeWriter(&buff, EscapeAll)
+	w.Trailer = func(i TrailerInfo) []string {
+		info = i
+		return []string{"x"}
+	}
+	is.NoError(w.Write([]string{"abc"}))
+	is.NoError(w.Close())
+	is.EqualValues(1, info.Records)
+	is.EqualValues(4, info.Bytes)
+
+	name := filepath.Join(t.TempDir(), "export.csv")
+	fw, err := CreateFile(name, EscapeAll, FileOpts{EstimatedSize: 1024})
+	is.NoError(err)
+	fw.Trailer = CountTrailer("TRL")
+	is.NoError(fw.Write([]string{"a"}))
+	is.NoError(fw.Close())
+	b, err := os.ReadFile(name)
+	is.NoError(err)
+	is.Equal("a\nTRL,1\n", string(b))
+}
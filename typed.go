@@ -0,0 +1,76 @@
+//go:build go1.18
+// +build go1.18
+
+package csv
+
+// ColumnOf describes a column of the records written by a [TypedEncoder].
+type ColumnOf[T any] struct {
+	Name  string         // Name of the column, in the header
+	Value func(T) string // Value of the column for a record
+	// Marshal, when not nil, returns the value of the column instead of
+	// Value, and its errors abort the record. See [ColumnFor].
+	Marshal func(T) (string, error)
+}
+
+// A TypedEncoder writes values of type T as records, one column per
+// [ColumnOf], without reflection. Values are sanitized like any field given
+// to [SafeWriter.Write].
+//
+// A TypedEncoder reuses its record between calls, so it must not be used
+// concurrently.
+type TypedEncoder[T any] struct {
+	cols   []ColumnOf[T]
+	record []string
+}
+
+// NewTypedEncoder returns a new TypedEncoder writing the given columns.
+func NewTypedEncoder[T any](cols []ColumnOf[T]) *TypedEncoder[T] {
+	return &TypedEncoder[T]{
+		cols:   cols,
+		record: make([]string, len(cols)),
+	}
+}
+
+// Header returns the names of the columns.
+func (e *TypedEncoder[T]) Header() []string {
+	header := make([]string, len(e.cols))
+	for i, col := range e.cols {
+		header[i] = col.Name
+	}
+	return header
+}
+
+// WriteHeader writes the names of the columns to w using
+// [SafeWriter.WriteHeader].
+func (e *TypedEncoder[T]) WriteHeader(w *SafeWriter) error {
+	return w.WriteHeader(e.Header())
+}
+
+// Encode writes v to w as a single record.
+func (e *TypedEncoder[T]) Encode(w *SafeWriter, v T) error {
+	for i, col := range e.cols {
+		if col.Marshal == nil {
+			e.record[i] = col.Value(v)
+			continue
+		}
+
+		value, err := col.Marshal(v)
+		if err != nil {
+			return err
+		}
+		e.record[i] = value
+	}
+	return w.Write(e.record)
+}
+
+// EncodeAll writes values to w using [TypedEncoder.Encode], then flushes w,
+// returning any error from the Flush.
+func (e *TypedEncoder[T]) EncodeAll(w *SafeWriter, values []T) error {
+	for _, v := range values {
+		if err := e.Encode(w, v); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,38 @@
+//go:build go1.18
+// +build go1.18
+
+package csv
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedEncoder(t *testing.T) {
+	is := assert.New(t)
+
+	type user struct {
+		ID   int
+		Name string
+	}
+
+	enc := NewTypedEncoder([]ColumnOf[user]{
+		{Name: "id", Value: func(u user) string { return strconv.Itoa(u.ID) }},
+		{Name: "name", Value: func(u user) string { return u.Name }},
+	})
+	is.Equal([]string{"id", "name"}, enc.Header())
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {Required: true}}
+
+	is.NoError(enc.WriteHeader(w))
+	is.NoError(enc.EncodeAll(w, []user{{ID: 1, Name: "alice"}, {ID: -2, Name: "=bob"}}))
+	is.Equal("id,name\n1,alice\n\" -2\",\" =bob\"\n", buff.String())
+
+	err := enc.Encode(w, user{ID: 3})
+	is.ErrorIs(err, ErrRequiredField)
+}
@@ -0,0 +1,56 @@
+package csv
+
+import "hash/fnv"
+
+// uniqueSet holds the hashes of the values written to a unique column.
+type uniqueSet map[uint64]struct{}
+
+// hashValue returns the hash under which field is tracked by a unique column.
+func hashValue(field string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(field))
+	return h.Sum64()
+}
+
+// checkUnique checks that field has not been written yet to the unique
+// column at position n. The value is only tracked once the record is
+// written, by commitUnique.
+func (w *SafeWriter) checkUnique(n int, col ColumnOpts, field string) (uint64, error) {
+	hash := hashValue(field)
+	set := w.unique[n]
+	if _, ok := set[hash]; ok {
+		return 0, ErrDuplicateValue
+	}
+	if col.UniqueLimit > 0 && len(set) >= col.UniqueLimit {
+		return 0, ErrUniqueLimit
+	}
+	return hash, nil
+}
+
+// commitUnique tracks the values of the unique columns of the record just
+// written.
+func (w *SafeWriter) commitUnique() {
+	for n, field := range w.fields {
+		if !field.unique {
+			continue
+		}
+
+		if w.unique == nil {
+			w.unique = make(map[int]uniqueSet)
+		}
+		if w.unique[n] == nil {
+			w.unique[n] = make(uniqueSet)
+		}
+		w.unique[n][field.hash] = struct{}{}
+	}
+}
+
+// hasUniqueColumns reports whether a column of w is unique.
+func (w *SafeWriter) hasUniqueColumns() bool {
+	for _, col := range w.Columns {
+		if col.Unique {
+			return true
+		}
+	}
+	return false
+}
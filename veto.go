@@ -0,0 +1,177 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// A ValidateFunc checks a record against an external policy, typically a
+// remote service, and returns an error to veto it. It may be called
+// concurrently, and must not modify record.
+type ValidateFunc func(ctx context.Context, record []string) error
+
+// A VetoError is returned when a [ValidateFunc] vetoes a record.
+type VetoError struct {
+	Index  int64    // Index of the record among the records given to Write, starting at 0
+	Record []string // Vetoed record
+	Err    error    // Error of the ValidateFunc
+
+	// Metadata of the writer, see [SafeWriter.Metadata].
+	Metadata map[string]string
+}
+
+func (e *VetoError) Error() string {
+	return fmt.Sprintf("csv: record %d vetoed: %v%s", e.Index, e.Err, formatMetadata(e.Metadata))
+}
+
+func (e *VetoError) Unwrap() error { return e.Err }
+
+// A ValidatingWriter checks records with a [ValidateFunc] before writing
+// them to a [SafeWriter], so that exports can be checked row by row against
+// an external policy service. Records are buffered and validated by
+// batches, with concurrent calls, and the records of a batch that are not
+// vetoed are written in order.
+//
+// The exported fields can be changed before the first call to Write.
+type ValidatingWriter struct {
+	// BatchSize is the number of records buffered before they are
+	// validated and written (100 when 0).
+	BatchSize int
+	// Concurrency is the maximum number of concurrent calls of the
+	// validator (8 when 0).
+	Concurrency int
+
+	ctx      context.Context
+	w        *SafeWriter
+	validate ValidateFunc
+	pending  [][]string
+	index    int64 // Index of the first pending record
+	vetoed   int64
+	err      error // Error of the context, stopping the writer
+	flushErr error // Error of the records written by the last Flush
+}
+
+// NewValidatingWriter returns a ValidatingWriter writing to w the records
+// accepted by validate, called with ctx.
+func NewValidatingWriter(ctx context.Context, w *SafeWriter, validate ValidateFunc) *ValidatingWriter {
+	return &ValidatingWriter{
+		ctx:      ctx,
+		w:        w,
+		validate: validate,
+	}
+}
+
+// WriteHeader writes the header with [SafeWriter.WriteHeader], without
+// validation. It must be called before the first record is written.
+func (v *ValidatingWriter) WriteHeader(header []string) error {
+	return v.w.WriteHeader(header)
+}
+
+// Write buffers a copy of record, then validates and writes the buffered
+// records if the batch is full.
+//
+// Vetoed records, reported as a [VetoError], and records rejected by the
+// [SafeWriter] are skipped: the first error is returned once the other
+// records of the batch are written. When the context is done, the records
+// of the batch are dropped, and every later call fails.
+func (v *ValidatingWriter) Write(record []string) error {
+	if v.err != nil {
+		return v.err
+	}
+
+	v.pending = append(v.pending, append([]string{}, record...))
+	if len(v.pending) < v.batchSize() {
+		return nil
+	}
+	return v.writeBatch()
+}
+
+// Flush validates and writes the buffered records, then flushes the
+// [SafeWriter]. To check if an error occurred, call
+// [ValidatingWriter.Error].
+func (v *ValidatingWriter) Flush() {
+	if v.err == nil && len(v.pending) > 0 {
+		v.flushErr = v.writeBatch()
+	}
+	v.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Flush, or that
+// stopped the writer.
+func (v *ValidatingWriter) Error() error {
+	if v.err != nil {
+		return v.err
+	}
+	if v.flushErr != nil {
+		return v.flushErr
+	}
+	return v.w.Error()
+}
+
+// Vetoed returns the number of records vetoed so far.
+func (v *ValidatingWriter) Vetoed() int64 {
+	return v.vetoed
+}
+
+func (v *ValidatingWriter) batchSize() int {
+	if v.BatchSize <= 0 {
+		return 100
+	}
+	return v.BatchSize
+}
+
+// writeBatch validates and writes the buffered records.
+func (v *ValidatingWriter) writeBatch() error {
+	records, index := v.pending, v.index
+	v.pending = nil
+	v.index += int64(len(records))
+
+	if err := v.ctx.Err(); err != nil {
+		v.err = err
+		return err
+	}
+
+	vetoes := v.check(records)
+
+	var first error
+	for i, record := range records {
+		err := vetoes[i]
+		if err != nil {
+			v.vetoed++
+			err = &VetoError{Index: index + int64(i), Record: record, Err: err, Metadata: v.w.Metadata}
+		} else {
+			err = v.w.Write(record)
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// check returns the error of the validator for every record.
+func (v *ValidatingWriter) check(records [][]string) []error {
+	concurrency := v.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+
+	var wg sync.WaitGroup
+	vetoes := make([]error, len(records))
+	sem := make(chan struct{}, concurrency)
+
+	for i := range records {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			vetoes[i] = v.validate(v.ctx, records[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return vetoes
+}
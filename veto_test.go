@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatingWriter(t *testing.T) {
+	is := assert.New(t)
+
+	errPolicy := errors.New("blocked country")
+	var running, peak int32
+	policy := func(ctx context.Context, record []string) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		if record[1] == "kp" {
+			return errPolicy
+		}
+		return nil
+	}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, EscapeAll)
+	w.Metadata = map[string]string{"export": "42"}
+	vw := NewValidatingWriter(context.Background(), w, policy)
+	vw.BatchSize = 3
+	vw.Concurrency = 2
+
+	is.NoError(vw.WriteHeader([]string{"id", "country"}))
+	is.NoError(vw.Write([]string{"1", "fr"}))
+	is.NoError(vw.Write([]string{"2", "kp"}))
+
+	err := vw.Write([]string{"=3", "us"})
+	is.ErrorIs(err, errPolicy)
+	is.Equal(&VetoError{Index: 1, Record: []string{"2", "kp"}, Err: errPolicy, Metadata: w.Metadata}, err)
+	is.EqualError(err, "csv: record 1 vetoed: blocked country (export=42)")
+
+	is.NoError(vw.Write([]string{"4", "kp"}))
+	is.NoError(vw.Write([]string{"5", "de"}))
+	vw.Flush()
+	is.ErrorIs(vw.Error(), errPolicy)
+	is.Equal("id,country\n1,fr\n\" =3\",us\n5,de\n", buff.String())
+	is.EqualValues(2, vw.Vetoed())
+	is.LessOrEqual(atomic.LoadInt32(&peak), int32(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	vw = NewValidatingWriter(ctx, w, policy)
+	is.NoError(vw.Write([]string{"6", "fr"}))
+	vw.Flush()
+	is.ErrorIs(vw.Error(), context.Canceled)
+	is.ErrorIs(vw.Write([]string{"7", "fr"}), context.Canceled)
+}
@@ -3,8 +3,10 @@ package csv
 import (
 	"bufio"
 	"errors"
+	"hash"
 	"io"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 )
@@ -16,7 +18,62 @@ type SafetyOpts struct {
 	EscapeCharMinus   bool
 	EscapeCharAt      bool
 	EscapeCharTab     bool
-	EscapeCharCR      bool
+	EscapeCharLF      bool // Fields starting with \n
+	// EscapeCharCR escapes fields starting with \r. For backward
+	// compatibility, it also escapes fields starting with \n, as it did
+	// before EscapeCharLF was added.
+	EscapeCharCR bool
+	// EscapeCharPipe escapes fields starting with |, used by DDE payloads
+	// such as =cmd|' /C calc'!A0 once the formula sign is implied.
+	EscapeCharPipe bool
+	// EscapeCharPercent escapes fields starting with %, which some import
+	// paths of LibreOffice treat specially.
+	EscapeCharPercent bool
+
+	// EscapeUNCPaths escapes fields starting with \\, such as
+	// \\server\share\file.xlsx, which some spreadsheet clients resolve when
+	// the file is opened, leaking credentials to the server.
+	EscapeUNCPaths bool
+	// EscapeFileURLs escapes fields starting with file://, in any case, for
+	// the same reason.
+	EscapeFileURLs bool
+
+	// TriggerRunes are additional leading characters escaped on top of the
+	// ones enabled by the EscapeChar options, such as '|', '%' or the
+	// fullwidth '＝' that some spreadsheet software reads as '='.
+	TriggerRunes []rune
+
+	// Strategy selects how the fields starting with a trigger are
+	// neutralized (prefixed with EscapePrefix when zero).
+	Strategy Strategy
+	// Replace returns the replacement of a field with [StrategyReplace]. A
+	// replacement that still starts with a trigger is prefixed.
+	Replace func(field string) string `json:"-"`
+	// SanitizeFunc, when set, is called with every field and the index of
+	// its column before the built-in sanitization, for organization-specific
+	// neutralization. When it returns true, its value replaces the built-in
+	// neutralization and is still quoted by the writer; otherwise the field
+	// is sanitized as usual.
+	SanitizeFunc func(field string, col int) (string, bool) `json:"-"`
+
+	// EscapePrefix is prepended to the fields that are escaped (a space
+	// when empty). A single quote is kept as a text marker by Excel and
+	// hidden from its cells, while a tab survives the trimming of values
+	// done by some parsers.
+	EscapePrefix string
+
+	// SkipLeadingSpace detects the triggers past leading Unicode whitespace,
+	// such as in " =SUM(A1)", which Excel still evaluates once trimmed. A
+	// whitespace EscapePrefix would be skipped as well, so fields are then
+	// prefixed with a single quote instead.
+	SkipLeadingSpace bool
+
+	// LongNumberDigits, when positive, makes fields made of at least this
+	// many digits start with a tab, so that spreadsheet software keeps them
+	// as text instead of rendering credit card numbers or EANs in scientific
+	// notation. Such fields are also quoted. Trusted numeric cells are
+	// guarded too, unlike [RawCell] and [SafeWriter.WriteRecordUnsafe].
+	LongNumberDigits int
 }
 
 var FullSafety = SafetyOpts{
@@ -26,7 +83,10 @@ var FullSafety = SafetyOpts{
 	EscapeCharMinus:   true,
 	EscapeCharAt:      true,
 	EscapeCharTab:     true,
+	EscapeCharLF:      true,
 	EscapeCharCR:      true,
+	EscapeCharPipe:    true,
+	EscapeCharPercent: true,
 }
 
 var EscapeAll = SafetyOpts{
@@ -36,7 +96,207 @@ var EscapeAll = SafetyOpts{
 	EscapeCharMinus:   true,
 	EscapeCharAt:      true,
 	EscapeCharTab:     true,
+	EscapeCharLF:      true,
 	EscapeCharCR:      true,
+	EscapeCharPipe:    true,
+	EscapeCharPercent: true,
+}
+
+// Strategy selects how a [SafeWriter] neutralizes the fields starting
+// with a trigger.
+type Strategy int
+
+const (
+	// StrategyPrefix prepends [SafetyOpts.EscapePrefix] to the field.
+	StrategyPrefix Strategy = iota
+	// StrategyStrip removes the leading triggers of the field.
+	StrategyStrip
+	// StrategyReject fails the record with a [ColumnError] wrapping
+	// [ErrDangerousField], holding the position and the value of the
+	// field, for pipelines where data must never be altered silently.
+	StrategyReject
+	// StrategyReplace writes the value returned by [SafetyOpts.Replace].
+	StrategyReplace
+)
+
+// escape neutralizes a field starting with a character that spreadsheet
+// software would interpret as the beginning of a formula, according to the
+// strategy of opts. col is the index of the column of field, given to
+// SanitizeFunc.
+func (opts SafetyOpts) escape(field string, col int) (string, error) {
+	if opts.SanitizeFunc != nil {
+		if sanitized, ok := opts.SanitizeFunc(field, col); ok {
+			return sanitized, nil
+		}
+	}
+
+	r, offset, ok := opts.triggerAt(field)
+	if !ok {
+		return field, nil
+	}
+
+	switch opts.Strategy {
+	case StrategyStrip:
+		for ok {
+			field = field[offset+opts.triggerLen(field[offset:], r):]
+			r, offset, ok = opts.triggerAt(field)
+		}
+		return field, nil
+	case StrategyReject:
+		return field, ErrDangerousField
+	case StrategyReplace:
+		if opts.Replace != nil {
+			field = opts.Replace(field)
+			if _, ok := opts.trigger(field); !ok {
+				return field, nil
+			}
+		}
+	}
+	return opts.prefix(field), nil
+}
+
+// prefix prepends the escape prefix of opts to field.
+func (opts SafetyOpts) prefix(field string) string {
+	prefix := opts.EscapePrefix
+	if prefix == "" {
+		prefix = " "
+	}
+	if opts.SkipLeadingSpace && strings.TrimLeftFunc(prefix, unicode.IsSpace) == "" {
+		prefix = "'"
+	}
+	return prefix + field
+}
+
+// Sanitize returns field as sanitized by a [SafeWriter] using opts, without
+// the quoting. Since it cannot fail, fields rejected by [StrategyReject] are
+// prefixed instead. SanitizeFunc is called with a column index of -1.
+func (opts SafetyOpts) Sanitize(field string) string {
+	escaped, err := opts.escape(field, -1)
+	if err != nil {
+		escaped = opts.prefix(field)
+	}
+	field = escaped
+	if opts.isLongNumber(field) {
+		field = "\t" + field
+	}
+	return field
+}
+
+// trigger returns the leading character of field that opts escapes, if any,
+// past leading whitespace with SkipLeadingSpace.
+func (opts SafetyOpts) trigger(field string) (rune, bool) {
+	r, _, ok := opts.triggerAt(field)
+	return r, ok
+}
+
+// triggerAt returns the trigger of field like trigger does, with its offset
+// in field.
+func (opts SafetyOpts) triggerAt(field string) (rune, int, bool) {
+	if r, ok := opts.leadingTrigger(field); ok || !opts.SkipLeadingSpace {
+		return r, 0, ok
+	}
+
+	offset := len(field) - len(strings.TrimLeftFunc(field, unicode.IsSpace))
+	r, ok := opts.leadingTrigger(field[offset:])
+	return r, offset, ok
+}
+
+// leadingTrigger returns the first character of field if opts escapes it.
+// For UNC paths and file URLs, it is the first character of the prefix.
+func (opts SafetyOpts) leadingTrigger(field string) (rune, bool) {
+	if len(field) == 0 {
+		return 0, false
+	}
+
+	r, _ := utf8.DecodeRuneInString(field)
+	switch {
+	case opts.isTrigger(r),
+		opts.EscapeUNCPaths && strings.HasPrefix(field, `\\`),
+		opts.EscapeFileURLs && len(field) >= len("file://") && strings.EqualFold(field[:len("file://")], "file://"):
+		return r, true
+	}
+	return 0, false
+}
+
+// triggerLen returns the length in bytes of the trigger r leading field:
+// the whole prefix for UNC paths and file URLs, the rune otherwise.
+func (opts SafetyOpts) triggerLen(field string, r rune) int {
+	switch {
+	case opts.isTrigger(r):
+		return utf8.RuneLen(r)
+	case opts.EscapeUNCPaths && strings.HasPrefix(field, `\\`):
+		return len(`\\`)
+	default:
+		return len("file://")
+	}
+}
+
+// isTrigger reports whether opts escapes fields starting with r.
+func (opts SafetyOpts) isTrigger(r rune) bool {
+	switch {
+	case opts.EscapeCharEqual && r == '=',
+		opts.EscapeCharPlus && r == '+',
+		opts.EscapeCharMinus && r == '-',
+		opts.EscapeCharAt && r == '@',
+		opts.EscapeCharTab && r == '\t',
+		opts.EscapeCharLF && r == '\n',
+		opts.EscapeCharCR && (r == '\r' || r == '\n'),
+		opts.EscapeCharPipe && r == '|',
+		opts.EscapeCharPercent && r == '%':
+		return true
+	}
+
+	for _, t := range opts.TriggerRunes {
+		if t == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Triggers returns the leading characters escaped by opts: the ones enabled
+// by the EscapeChar options, followed by TriggerRunes, without duplicates.
+// UNC paths and file URLs are matched by prefix, and are not included.
+func (opts SafetyOpts) Triggers() []rune {
+	var triggers []rune
+	for _, r := range append([]rune("=+-@\t\n\r|%"), opts.TriggerRunes...) {
+		if opts.isTrigger(r) && !containsRune(triggers, r) {
+			triggers = append(triggers, r)
+		}
+	}
+	return triggers
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, other := range runes {
+		if other == r {
+			return true
+		}
+	}
+	return false
+}
+
+// isLongNumber reports whether field is a digit string long enough to be
+// mangled by spreadsheet software.
+func (opts SafetyOpts) isLongNumber(field string) bool {
+	if opts.LongNumberDigits <= 0 || len(field) < opts.LongNumberDigits {
+		return false
+	}
+
+	for i := 0; i < len(field); i++ {
+		if field[i] < '0' || field[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// A RecordWriter writes records, like [SafeWriter], [FixedWidthWriter] and
+// [encoding/csv.Writer] do.
+type RecordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
 }
 
 // A SafeWriter writes records using CSV encoding.
@@ -51,22 +311,166 @@ var EscapeAll = SafetyOpts{
 // If [SafeWriter.UseCRLF] is true,
 // the SafeWriter ends each output line with \r\n instead of \n.
 //
+// [SafeWriter.Columns] configures individual columns, by position.
+//
 // The writes of individual records are buffered.
 // After all data has been written, the client should call the
 // [SafeWriter.Flush] method to guarantee all data has been forwarded to
 // the underlying [io.Writer].  Any errors that occurred should
 // be checked by calling the [SafeWriter.Error] method.
 type SafeWriter struct {
-	Comma   rune // Field delimiter (set to ',' by NewSafeWriter)
-	UseCRLF bool // True to use \r\n as the line terminator
-	w       *bufio.Writer
-	opts    SafetyOpts
+	Comma      rune         // Field delimiter (set to ',' by NewSafeWriter)
+	UseCRLF    bool         // True to use \r\n as the line terminator
+	Columns    []ColumnOpts // Per-column options, by field position
+	BoolFormat BoolFormat   // Rendering of BoolCell values (true/false when zero)
+
+	// RenameDuplicates makes WriteHeader rename repeated names as name_2,
+	// name_3... instead of failing, like spreadsheet software does.
+	RenameDuplicates bool
+
+	// HeaderCase normalizes the names given to WriteHeader.
+	HeaderCase HeaderCase
+
+	// Flatten configures the flattening of nested structs by WriteStruct.
+	// It must be set before the first call to WriteStruct.
+	Flatten FlattenOpts
+
+	// AutoHeader makes WriteStruct and WriteMap write a header derived from
+	// their first record when none was written: the [FlattenOpts.StructHeader]
+	// of the struct, or the sorted keys of the map. Later named records are
+	// validated against the header: struct fields and map keys missing from
+	// it are reported as [ErrUnknownColumn] instead of being ignored.
+	AutoHeader bool
+
+	// Canonical writes a single, stable form of CSV, so that exports of the
+	// same records are byte-for-byte identical: fields are quoted only when
+	// required, ignoring [SafetyOpts.ForceDoubleQuotes] and quote modes,
+	// and every record, including the last one, ends with \n, ignoring
+	// UseCRLF. Sanitization still applies, including the quoting of phone
+	// numbers.
+	Canonical bool
+
+	// FlushThreshold, when positive, flushes the output buffer as soon as
+	// it holds at least this many bytes, once a record is written. Low
+	// values reduce the latency of streaming responses, and values above
+	// the default buffer size of 4096 bytes grow the buffer, making fewer
+	// and larger writes to the underlying [io.Writer].
+	FlushThreshold int
+
+	// MaxScratchSize, when positive, caps the capacity in bytes of the
+	// scratch buffer kept between writes to encode records. A buffer grown
+	// past it by a large record is released once the record is written.
+	MaxScratchSize int
+
+	// DoubleBuffer writes the output buffer to the underlying [io.Writer]
+	// on a separate goroutine, while the next records are encoded into a
+	// second buffer, which smooths the throughput of slow writers such as
+	// network connections or cloud storage uploads. The buffers hold
+	// FlushThreshold bytes, or 4096 bytes when it is lower. Flush and Error
+	// wait for the pending write.
+	DoubleBuffer bool
+
+	// FlushHTTP makes every flush of the output buffer, by Flush or once
+	// FlushThreshold is reached, also flush the underlying [io.Writer] when
+	// it implements [net/http.Flusher], such as the ResponseWriter of a
+	// streamed download, so that records reach the client as they are
+	// written. The writes then block while the client is slow to read,
+	// which bounds the memory used by the handler to the output buffer.
+	FlushHTTP bool
+
+	// FlushInterval, when positive, throttles the HTTP flushes triggered
+	// by FlushThreshold to one per interval, avoiding the cost of sending
+	// many small chunks to slow clients. Flush always flushes.
+	FlushInterval time.Duration
+
+	// Trailer, when not nil, builds the trailer written by Close, such as
+	// [CountTrailer] or [ChecksumTrailer], unless WriteTrailer was called.
+	// Bytes are then hashed as they are written.
+	Trailer func(TrailerInfo) []string
+
+	// Envelope, when not nil, declares lines written before the first
+	// record and by Close.
+	Envelope *Envelope
+
+	// Summary, when not nil, receives the [ExportSummary] of the output as
+	// a line of JSON when Close is called. Bytes are then hashed as they are
+	// written.
+	Summary io.Writer
+
+	// Audit, when not nil, is called with every record written, header and
+	// trailer excluded, such as to feed an audit stream or a manifest.
+	Audit func(AuditRecord)
+
+	// IdempotencyKeys derives a stable key for every record reported to
+	// Audit from its encoded bytes, without the line terminator, so that the
+	// consumers of a file delivered several times can ingest every record
+	// exactly once. Identical records get the same key.
+	IdempotencyKeys bool
+
+	// Binary selects how fields holding binary data, such as a NUL byte or
+	// a high ratio of control bytes, are written. Binary fields are detected
+	// before the transformers of their column are applied.
+	Binary BinaryPolicy
+
+	// MaxErrors, when positive, aborts the export once more than MaxErrors
+	// records have been rejected by the validation or the sanitization of
+	// their fields, for callers skipping such records, so that broken
+	// upstream data cannot silently ship a half-empty file. Every later
+	// write, and Error, then fail with [ErrTooManyErrors].
+	MaxErrors int
+
+	// Quarantine, when not nil, receives the records rejected by the
+	// validation or the sanitization of their fields, as given to the
+	// writer, followed by the reason of the rejection, so that data teams
+	// can triage them later. WriteHeader also writes the header to
+	// Quarantine, followed by [QuarantineColumn], unless it already holds
+	// records. Quarantine must accept any field, such as by using
+	// [StrategyPrefix], and must be flushed by the caller.
+	Quarantine *SafeWriter
+
+	// Metadata, such as an export ID or a tenant, is attached to the errors
+	// returned by the writer, and appended to their message, so that the
+	// failures of concurrent exports can be told apart.
+	Metadata map[string]string
+
+	dst        io.Writer
+	w          *bufio.Writer
+	opts       SafetyOpts
+	stats      Stats
+	fields     []preparedField
+	buf        []byte   // Scratch buffer holding the record being written
+	batch      bool     // True while WriteAll fills buf with several records
+	header     []string // Output header
+	input      []string // Header of the input records, before projection
+	selected   []string
+	titles     map[string]string
+	computed   []computedColumn
+	projection []int
+	projected  []string
+	structs    structCache
+	vars       *writerVars  // Counters published by PublishExpvar
+	async      *asyncWriter // Pending writes when DoubleBuffer is set
+	flushed    time.Time    // Time of the last HTTP flush
+	unique     map[int]uniqueSet
+	headers    int64     // Header records written, 0 or 1
+	trailed    bool      // True once the trailer is written
+	digest     hash.Hash // Checksum of the output, when Trailer, Envelope or Summary is set
+	digested   int64     // Bytes hashed into digest
+	opened     time.Time // Time the envelope was opened, zero before
+	closed     bool      // True once the footer of the envelope is written
+	aborted    error     // Error stopping the writer once MaxErrors is exceeded
+	started    time.Time // Time the first line was written, zero before
+	summarized bool      // True once the summary is written
+
+	lastSanitized int64  // Sanitized fields of the last record written
+	lastKey       string // Idempotency key of the last record written
 }
 
 // NewSafeWriter returns a new SafeWriter that writes to w.
 func NewSafeWriter(w io.Writer, opts SafetyOpts) *SafeWriter {
 	return &SafeWriter{
 		Comma: ',',
+		dst:   w,
 		w:     bufio.NewWriter(w),
 		opts:  opts,
 	}
@@ -77,119 +481,396 @@ func NewSafeWriter(w io.Writer, opts SafetyOpts) *SafeWriter {
 // Writes are buffered, so [SafeWriter.Flush] must eventually be called to ensure
 // that the record is written to the underlying [io.Writer].
 func (w *SafeWriter) Write(record []string) error {
+	return w.writeRecord(record, CellPolicy{})
+}
+
+// WriteRecordUnsafe writes a single CSV record like [SafeWriter.Write], but
+// without any sanitization: only quoting is applied.
+//
+// It must only be used for records that are known to be safe.
+func (w *SafeWriter) WriteRecordUnsafe(record []string) error {
+	if err := w.writeRecord(record, CellPolicy{Trusted: true, Raw: true}); err != nil {
+		return err
+	}
+	w.stats.TrustedRecords++
+	return nil
+}
+
+// WriteWithOpts writes a single CSV record like [SafeWriter.Write], using opts
+// instead of the options of the writer for this record only.
+//
+// This is meant for rows built by the application itself, such as a summary
+// row holding intentional formulas, and must not be used for user input.
+func (w *SafeWriter) WriteWithOpts(record []string, opts SafetyOpts) error {
+	saved := w.opts
+	w.opts = opts
+	defer func() { w.opts = saved }()
+
+	return w.Write(record)
+}
+
+// writeRecord writes a single CSV record, encoding every field with policy.
+func (w *SafeWriter) writeRecord(record []string, policy CellPolicy) error {
 	if !validDelim(w.Comma) {
 		return errInvalidDelim
 	}
+	if w.aborted != nil {
+		return w.aborted
+	}
 
-	for n, field := range record {
-		if n > 0 {
-			if _, err := w.w.WriteRune(w.Comma); err != nil {
-				return err
-			}
+	w.fields = w.fields[:0]
+	for n, field := range w.project(record) {
+		prepared, err := w.prepareField(n, field, policy)
+		if err != nil {
+			return w.reject(record, err)
 		}
+		w.fields = append(w.fields, prepared)
+	}
 
-		if len(field) > 0 {
-			// ADDED BY @samber ON 2024-12-05
-			switch {
-			case w.opts.EscapeCharEqual && field[0] == '=':
-				field = " " + field
-			case w.opts.EscapeCharPlus && field[0] == '+':
-				field = " " + field
-			case w.opts.EscapeCharMinus && field[0] == '-':
-				field = " " + field
-			case w.opts.EscapeCharAt && field[0] == '@':
-				field = " " + field
-			case w.opts.EscapeCharTab && field[0] == '\t':
-				field = " " + field
-			case w.opts.EscapeCharCR && field[0] == '\n':
-				field = " " + field
-			}
+	if err := w.prepareComputed(record); err != nil {
+		return w.reject(record, err)
+	}
+	if err := w.writeFields(); err != nil {
+		return err
+	}
+	w.audit()
+	return nil
+}
+
+// preparedField is a field ready to be written.
+type preparedField struct {
+	value     string
+	quote     QuoteMode
+	sanitized bool
+	unique    bool   // True when the field belongs to a unique column
+	hash      uint64 // Hash of the value of a unique column
+}
+
+// prepareField transforms, sanitizes and pads the field at position n.
+// Every field of a record is prepared before the first one is written, so
+// that a rejected record never leaves a partial line behind.
+func (w *SafeWriter) prepareField(n int, field string, policy CellPolicy) (preparedField, error) {
+	col := w.column(n)
+
+	field, err := w.Binary.apply(field)
+	if err != nil {
+		return preparedField{}, w.columnError(n, field, err)
+	}
+
+	transformed, err := col.transform(field)
+	if err != nil {
+		return preparedField{}, w.columnError(n, field, err)
+	}
+	if transformed != field {
+		// The value is no longer the one the cell vouched for.
+		policy.Trusted, policy.Raw = false, false
+	}
+	field = transformed
+
+	if err := col.validate(field); err != nil {
+		return preparedField{}, w.columnError(n, field, err)
+	}
+
+	prepared := preparedField{quote: w.quoteMode(policy.Quote)}
+
+	if col.Unique {
+		hash, err := w.checkUnique(n, col, field)
+		if err != nil {
+			return preparedField{}, w.columnError(n, field, err)
 		}
+		prepared.hash, prepared.unique = hash, true
+	}
 
-		// If we don't have to have a quoted field then just
-		// write out the field and continue to the next field.
-		if !w.fieldNeedsQuotes(field) {
-			if _, err := w.w.WriteString(field); err != nil {
-				return err
-			}
-			continue
+	// ADDED BY @samber ON 2024-12-05
+	switch {
+	case policy.Raw:
+	case policy.Trusted:
+		field = w.guardLongNumber(col, field)
+	case col.Phone && isPhoneNumber(field):
+		prepared.quote = QuoteAlways
+		if col.TextHint == TextHintNone && isNumericPhone(field) {
+			field = "\t" + field
+		}
+	default:
+		escaped, err := w.opts.escape(field, n)
+		if err != nil {
+			return prepared, w.columnError(n, field, err)
+		}
+		if escaped != field {
+			field = escaped
+			prepared.sanitized = true
 		}
 
-		if err := w.w.WriteByte('"'); err != nil {
-			return err
+		field = w.guardLongNumber(col, field)
+	}
+
+	if prefix := col.TextHint.prefix(); prefix != "" && field != "" {
+		field = prefix + field
+		prepared.quote = QuoteAlways
+	}
+
+	prepared.value = col.pad(field)
+	return prepared, nil
+}
+
+// guardLongNumber makes field start with a tab when it is a long number,
+// unless the column already has a text hint.
+func (w *SafeWriter) guardLongNumber(col ColumnOpts, field string) string {
+	if col.TextHint == TextHintNone && w.opts.isLongNumber(field) {
+		return "\t" + field
+	}
+	return field
+}
+
+// quoteMode returns the quote mode of a field written with quote.
+func (w *SafeWriter) quoteMode(quote QuoteMode) QuoteMode {
+	if w.Canonical {
+		return QuoteMinimal
+	}
+	return quote
+}
+
+// writeFields writes the prepared fields as a single CSV record. The record
+// is encoded into a scratch buffer first, then written at once, unless a
+// batch is being encoded.
+func (w *SafeWriter) writeFields() error {
+	if w.aborted != nil {
+		return w.aborted
+	}
+	if w.trailed {
+		return errRecordAfterTrailer
+	}
+	if w.started.IsZero() {
+		w.started = time.Now()
+	}
+
+	var sanitized int64
+
+	if !w.batch {
+		w.buf = w.buf[:0]
+	}
+	if err := w.openEnvelope(); err != nil {
+		return err
+	}
+	start := len(w.buf)
+	for n, field := range w.fields {
+		if n > 0 {
+			w.buf = appendRune(w.buf, w.Comma)
 		}
-		for len(field) > 0 {
-			// Search for special characters.
-			i := strings.IndexAny(field, "\"\r\n")
-			if i < 0 {
-				i = len(field)
-			}
 
-			// Copy verbatim everything before the special character.
-			if _, err := w.w.WriteString(field[:i]); err != nil {
-				return err
-			}
-			field = field[i:]
-
-			// Encode the special character.
-			if len(field) > 0 {
-				var err error
-				switch field[0] {
-				case '"':
-					_, err = w.w.WriteString(`""`)
-				case '\r':
-					if !w.UseCRLF {
-						err = w.w.WriteByte('\r')
-					}
-				case '\n':
-					if w.UseCRLF {
-						_, err = w.w.WriteString("\r\n")
-					} else {
-						err = w.w.WriteByte('\n')
-					}
-				}
-				field = field[1:]
-				if err != nil {
-					return err
-				}
-			}
+		w.buf = w.appendField(w.buf, field.value, field.quote)
+
+		if field.sanitized {
+			sanitized++
 		}
-		if err := w.w.WriteByte('"'); err != nil {
+	}
+	if w.IdempotencyKeys {
+		w.lastKey = rowKey(w.buf[start:])
+	}
+	w.lastSanitized = sanitized
+	w.buf = append(w.buf, w.lineEnd()...)
+
+	if !w.batch {
+		if err := w.writeBuffer(); err != nil {
 			return err
 		}
 	}
-	var err error
-	if w.UseCRLF {
-		_, err = w.w.WriteString("\r\n")
-	} else {
-		err = w.w.WriteByte('\n')
+	w.commitUnique()
+	w.stats.Records++
+	w.stats.SanitizedFields += sanitized
+	if w.vars != nil {
+		w.vars.addRecord(sanitized)
+	}
+
+	if w.FlushThreshold > 0 && w.w.Buffered() >= w.FlushThreshold {
+		return w.autoFlush()
+	}
+	return nil
+}
+
+// writeBuffer writes the content of the scratch buffer to the output buffer.
+func (w *SafeWriter) writeBuffer() error {
+	if err := w.growBuffer(); err != nil {
+		return err
+	}
+
+	n, err := w.w.Write(w.buf)
+	if w.vars != nil {
+		w.vars.addBytes(n)
+	}
+	w.digestBuffer(n)
+	if w.MaxScratchSize > 0 && cap(w.buf) > w.MaxScratchSize {
+		w.buf = nil
 	}
 	return err
 }
 
+// writeBatch writes records using write, encoding them all into the scratch
+// buffer, sized upfront, before writing it at once and flushing. The records
+// preceding a rejected one are still written.
+func (w *SafeWriter) writeBatch(records [][]string, write func(record []string) error) error {
+	w.buf = w.buf[:0]
+	if size := w.estimateSize(records); cap(w.buf) < size {
+		w.buf = make([]byte, 0, size)
+	}
+
+	var err error
+	w.batch = true
+	for _, record := range records {
+		if err = write(record); err != nil {
+			break
+		}
+	}
+	w.batch = false
+
+	if werr := w.writeBuffer(); werr != nil {
+		return werr
+	}
+	if err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+// estimateSize returns the expected size of records once encoded, assuming
+// that few fields need escaping.
+func (w *SafeWriter) estimateSize(records [][]string) int {
+	size := 0
+	for _, record := range records {
+		for _, field := range record {
+			size += len(field) + utf8.RuneLen(w.Comma)
+			if w.opts.ForceDoubleQuotes {
+				size += 2
+			}
+		}
+		size += len(w.lineEnd())
+	}
+	return size
+}
+
+// growBuffer replaces the output buffer by one able to hold
+// [SafeWriter.FlushThreshold] bytes, if it is smaller, and by one writing
+// asynchronously when [SafeWriter.DoubleBuffer] is set.
+func (w *SafeWriter) growBuffer() error {
+	grow := w.FlushThreshold > w.w.Size()
+	if !grow && (!w.DoubleBuffer || w.async != nil) {
+		return nil
+	}
+
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+
+	size := w.w.Size()
+	if grow {
+		size = w.FlushThreshold
+	}
+	var dst io.Writer = w.dst
+	if w.DoubleBuffer {
+		if w.async == nil {
+			w.async = &asyncWriter{w: w.dst}
+		}
+		dst = w.async
+	}
+	w.w = bufio.NewWriterSize(dst, size)
+	return nil
+}
+
+// appendField appends a single field to buf, along with any necessary
+// quoting.
+func (w *SafeWriter) appendField(buf []byte, field string, quote QuoteMode) []byte {
+	// If we don't have to have a quoted field then just
+	// write out the field and continue to the next field.
+	if !w.fieldNeedsQuotes(field, quote) {
+		return append(buf, field...)
+	}
+
+	buf = append(buf, '"')
+
+	// Copy verbatim the runs of regular characters between the special
+	// characters, which are encoded as they are found.
+	start := 0
+	for i := 0; i < len(field); i++ {
+		var encoded string
+		switch field[i] {
+		case '"':
+			encoded = `""`
+		case '\r':
+			if !w.UseCRLF || w.Canonical {
+				encoded = "\r"
+			}
+		case '\n':
+			encoded = w.lineEnd()
+		default:
+			continue
+		}
+
+		buf = append(buf, field[start:i]...)
+		buf = append(buf, encoded...)
+		start = i + 1
+	}
+
+	buf = append(buf, field[start:]...)
+	return append(buf, '"')
+}
+
+// appendRune appends the UTF-8 encoding of r to buf.
+func appendRune(buf []byte, r rune) []byte {
+	if r < utf8.RuneSelf {
+		return append(buf, byte(r))
+	}
+
+	var encoded [utf8.UTFMax]byte
+	return append(buf, encoded[:utf8.EncodeRune(encoded[:], r)]...)
+}
+
+// lineEnd returns the line terminator of the writer.
+func (w *SafeWriter) lineEnd() string {
+	if w.UseCRLF && !w.Canonical {
+		return "\r\n"
+	}
+	return "\n"
+}
+
 // Flush writes any buffered data to the underlying [io.Writer].
 // To check if an error occurred during Flush, call [SafeWriter.Error].
 func (w *SafeWriter) Flush() {
-	_ = w.w.Flush()
+	_ = w.flush()
 }
 
 // Error reports any error that has occurred during
-// a previous [SafeWriter.Write] or [SafeWriter.Flush].
+// a previous [SafeWriter.Write] or [SafeWriter.Flush], or the abort of the
+// export once [SafeWriter.MaxErrors] is exceeded.
 func (w *SafeWriter) Error() error {
+	if w.aborted != nil {
+		return w.aborted
+	}
 	_, err := w.w.Write(nil)
+	if err == nil && w.async != nil {
+		err = w.async.wait()
+	}
 	return err
 }
 
 // WriteAll writes multiple CSV records to w using [SafeWriter.Write] and
 // then calls [SafeWriter.Flush], returning any error from the Flush.
+//
+// Records are encoded into a single buffer, sized from the length of their
+// fields, and written at once, which is faster than calling
+// [SafeWriter.Write] repeatedly.
 func (w *SafeWriter) WriteAll(records [][]string) error {
-	for _, record := range records {
-		err := w.Write(record)
-		if err != nil {
-			return err
-		}
-	}
-	return w.w.Flush()
+	return w.writeBatch(records, w.Write)
+}
+
+// WriteAllTrusted writes multiple CSV records to w using
+// [SafeWriter.WriteRecordUnsafe] and then calls [SafeWriter.Flush], returning
+// any error from the Flush.
+//
+// It is meant for batches produced by the system itself, when a pipeline
+// mixes them with user-generated data. Trusted records are counted in
+// [Stats.TrustedRecords].
+func (w *SafeWriter) WriteAllTrusted(records [][]string) error {
+	return w.writeBatch(records, w.WriteRecordUnsafe)
 }
 
 // fieldNeedsQuotes reports whether our field must be enclosed in quotes.
@@ -204,7 +885,13 @@ func (w *SafeWriter) WriteAll(records [][]string) error {
 // Not quoting the empty string also makes this package match the behavior
 // of Microsoft Excel and Google Drive.
 // For Postgres, quote the data terminating string `\.`.
-func (w *SafeWriter) fieldNeedsQuotes(field string) bool {
+// The quote mode of the field can force quoting, or ignore
+// [SafetyOpts.ForceDoubleQuotes].
+func (w *SafeWriter) fieldNeedsQuotes(field string, quote QuoteMode) bool {
+	if quote == QuoteAlways {
+		return true
+	}
+
 	if field == "" {
 		return false
 	}
@@ -214,7 +901,7 @@ func (w *SafeWriter) fieldNeedsQuotes(field string) bool {
 	}
 
 	// ADDED BY @samber ON 2024-12-05
-	if w.opts.ForceDoubleQuotes {
+	if w.opts.ForceDoubleQuotes && quote == QuoteAuto {
 		return true
 	}
 
@@ -1,6 +1,9 @@
 package csv
 
 import (
+	stdcsv "encoding/csv"
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
@@ -146,6 +149,259 @@ plop"
 	)
 }
 
+func TestWriteRecordUnsafe(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, FullSafety)
+	must(w.Write([]string{"=A1", "-1"}))
+	must(w.WriteRecordUnsafe([]string{"=A1", "-1"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\" =A1\",\" -1\"\n\"=A1\",\"-1\"\n", buff.String())
+}
+
+func TestWriteWithOpts(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, FullSafety)
+	must(w.Write([]string{"item", "=A1"}))
+	must(w.WriteWithOpts([]string{"total", "=SUM(B1:B1)"}, SafetyOpts{}))
+	must(w.Write([]string{"item", "=A1"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"item\",\" =A1\"\ntotal,=SUM(B1:B1)\n\"item\",\" =A1\"\n", buff.String())
+	is.Equal(FullSafety, w.opts)
+}
+
+func TestEscapeCharLFCR(t *testing.T) {
+	is := assert.New(t)
+
+	write := func(opts SafetyOpts) string {
+		var buff strings.Builder
+		w := NewSafeWriter(&buff, opts)
+		must(w.Write([]string{"\rcmd", "\ncmd", "a\r"}))
+		w.Flush()
+		must(w.Error())
+		return buff.String()
+	}
+
+	is.Equal("\"\rcmd\",\" \ncmd\",\"a\r\"\n", write(SafetyOpts{EscapeCharLF: true}))
+	is.Equal("\" \rcmd\",\" \ncmd\",\"a\r\"\n", write(SafetyOpts{EscapeCharCR: true}))
+	is.Equal([]rune{'\n'}, SafetyOpts{EscapeCharLF: true}.Triggers())
+	is.Equal([]rune{'\n', '\r'}, SafetyOpts{EscapeCharCR: true}.Triggers())
+}
+
+func TestSkipLeadingSpace(t *testing.T) {
+	is := assert.New(t)
+
+	record := []string{" =SUM(A1)", "\u00a0\u3000@cmd", " ok", "\t-1"}
+	write := func(opts SafetyOpts) string {
+		var buff strings.Builder
+		w := NewSafeWriter(&buff, opts)
+		must(w.Write(record))
+		w.Flush()
+		must(w.Error())
+		return buff.String()
+	}
+
+	is.Equal("\" =SUM(A1)\",\"\u00a0\u3000@cmd\",\" ok\",\" \t-1\"\n", write(EscapeAll))
+
+	opts := EscapeAll
+	opts.SkipLeadingSpace = true
+	is.Equal("' =SUM(A1),'\u00a0\u3000@cmd,\" ok\",'\t-1\n", write(opts))
+
+	opts.EscapeCharTab = false
+	opts.EscapePrefix = "\t"
+	is.Equal("' =SUM(A1),'\u00a0\u3000@cmd,\" ok\",'\t-1\n", write(opts))
+
+	opts.Strategy = StrategyStrip
+	is.Equal("SUM(A1),cmd,\" ok\",1\n", write(opts))
+
+	opts.Strategy = StrategyPrefix
+	opts.EscapePrefix = "#"
+	is.Equal("# =SUM(A1)", opts.Sanitize(" =SUM(A1)"))
+	dangerous, reason := IsDangerous("  +1", opts)
+	is.True(dangerous)
+	is.Equal(ReasonFormula, reason)
+}
+
+func TestEscapeCharPipe(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, SafetyOpts{EscapeCharPipe: true})
+	is.NoError(w.Write([]string{"|cmd' /C calc'!A0", "=cmd|' /C calc'!A0", "a|b"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\" |cmd' /C calc'!A0\",=cmd|' /C calc'!A0,a|b\n", buff.String())
+	is.Equal(" |cmd", FullSafety.Sanitize("|cmd"))
+}
+
+func TestEscapeCharPercent(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, SafetyOpts{EscapeCharPercent: true})
+	is.NoError(w.Write([]string{"%SYSTEMROOT%", "50%", "|a"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\" %SYSTEMROOT%\",50%,|a\n", buff.String())
+	is.Equal(" %d", EscapeAll.Sanitize("%d"))
+}
+
+func TestLongNumberDigits(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+
+	w := NewSafeWriter(&buff, SafetyOpts{LongNumberDigits: 12})
+	must(w.Write([]string{"4111111111111111", "12345678901", "123456789012", "1234-5678-9012", ""}))
+	must(w.WriteCells([]Cell{NumberCell(4111111111111111), StringCell("4111111111111111"), Raw("4111111111111111")}))
+	must(w.WriteRecordUnsafe([]string{"4111111111111111"}))
+	must(w.WriteCells([]Cell{IntCell(4111111111111111), UintCell(4111111111111111), IntCell(-4111111111111111), IntCell(42)}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"\t4111111111111111\",12345678901,\"\t123456789012\",1234-5678-9012,\n\"\t4111111111111111\",\"\t4111111111111111\",4111111111111111\n4111111111111111\n\"\t4111111111111111\",\"\t4111111111111111\",-4111111111111111,42\n", buff.String())
+	is.Zero(w.Stats().SanitizedFields)
+}
+
+func TestEscapePrefix(t *testing.T) {
+	is := assert.New(t)
+
+	for prefix, expected := range map[string]string{
+		"":   "\" =A1\",\" @b\",c\n",
+		"'":  "'=A1,'@b,c\n",
+		"\t": "\"\t=A1\",\"\t@b\",c\n",
+	} {
+		var buff strings.Builder
+
+		opts := EscapeAll
+		opts.EscapePrefix = prefix
+		w := NewSafeWriter(&buff, opts)
+		must(w.Write([]string{"=A1", "@b", "c"}))
+		w.Flush()
+		is.NoError(w.Error())
+		is.Equal(expected, buff.String(), prefix)
+	}
+}
+
+func TestStrategy(t *testing.T) {
+	is := assert.New(t)
+
+	write := func(opts SafetyOpts, record []string) (string, error) {
+		var buff strings.Builder
+		w := NewSafeWriter(&buff, opts)
+		err := w.Write(record)
+		w.Flush()
+		return buff.String(), err
+	}
+
+	opts := EscapeAll
+	opts.Strategy = StrategyStrip
+	out, err := write(opts, []string{"=-A1", "@b", "c", "=="})
+	is.NoError(err)
+	is.Equal("A1,b,c,\n", out)
+
+	opts.EscapeUNCPaths = true
+	opts.EscapeFileURLs = true
+	out, err = write(opts, []string{"file://x", `\\host\share`, "FILE://=y"})
+	is.NoError(err)
+	is.Equal("x,host\\share,y\n", out)
+	opts.EscapeUNCPaths = false
+	opts.EscapeFileURLs = false
+
+	opts.Strategy = StrategyReject
+	_, err = write(opts, []string{"a", "=A1"})
+	is.ErrorIs(err, ErrDangerousField)
+	is.EqualError(err, "csv: record 0, column 1: field would be interpreted as a formula")
+	is.Equal(" =A1", opts.Sanitize("=A1"))
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, opts)
+	is.ErrorIs(w.WriteHeader([]string{"id", "=name"}), ErrDangerousField)
+	is.ErrorIs(w.WriteTrailer([]string{"=TRL"}), ErrDangerousField)
+
+	opts.Strategy = StrategyReplace
+	opts.Replace = func(field string) string {
+		if field == "=A1" {
+			return "[formula]"
+		}
+		return "+" + field
+	}
+	out, err = write(opts, []string{"=A1", "@b", "c"})
+	is.NoError(err)
+	is.Equal("[formula],\" +@b\",c\n", out)
+
+	opts.Replace = nil
+	out, err = write(opts, []string{"=A1"})
+	is.NoError(err)
+	is.Equal("\" =A1\"\n", out)
+}
+
+func TestStrategyRejectRecord(t *testing.T) {
+	is := assert.New(t)
+
+	opts := EscapeAll
+	opts.Strategy = StrategyReject
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, opts)
+	w.Metadata = map[string]string{"export": "42"}
+	is.NoError(w.WriteHeader([]string{"id", "name", "cmd"}))
+	is.NoError(w.Write([]string{"1", "alice", "ok"}))
+
+	err := w.Write([]string{"2", "bob", "=cmd|' /C calc'!A0"})
+	var columnErr *ColumnError
+	is.True(errors.As(err, &columnErr))
+	is.Equal(&ColumnError{Row: 2, Column: 2, Name: "cmd", Value: "=cmd|' /C calc'!A0", Err: ErrDangerousField, Metadata: w.Metadata}, columnErr)
+	is.ErrorIs(err, ErrDangerousField)
+
+	is.ErrorIs(w.WriteCells([]Cell{StringCell("3"), StringCell("carol"), StringCell("@SUM(1)")}), ErrDangerousField)
+	is.NoError(w.WriteCells([]Cell{StringCell("3"), StringCell("carol"), Formula("=SUM(1)")}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("id,name,cmd\n1,alice,ok\n3,carol,=SUM(1)\n", buff.String())
+	is.EqualValues(3, w.Stats().Records)
+}
+
+func TestSanitizeFunc(t *testing.T) {
+	is := assert.New(t)
+
+	var cols []int
+	opts := EscapeAll
+	opts.SanitizeFunc = func(field string, col int) (string, bool) {
+		cols = append(cols, col)
+		if col == 2 {
+			return field, true // Trusted column
+		}
+		if _, ok := EscapeAll.trigger(field); ok {
+			return "'" + field + "'", true
+		}
+		return field, false
+	}
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, opts)
+	is.NoError(w.WriteHeader([]string{"a", "b", "c"}))
+	is.NoError(w.Write([]string{"=A1", "x,y", "-1"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("a,b,c\n'=A1',\"x,y\",-1\n", buff.String())
+	is.Equal([]int{0, 1, 2, 0, 1, 2}, cols)
+	is.Equal("'@b'", opts.Sanitize("@b"))
+
+	opts.SanitizeFunc = func(field string, col int) (string, bool) {
+		return "", false
+	}
+	is.Equal(" =A1", opts.Sanitize("=A1"))
+}
+
 func TestNewSafeWriterNoOpts(t *testing.T) {
 	is := assert.New(t)
 
@@ -165,7 +421,10 @@ func TestFullSafety(t *testing.T) {
 	is.True(EscapeAll.EscapeCharMinus)
 	is.True(EscapeAll.EscapeCharAt)
 	is.True(EscapeAll.EscapeCharTab)
+	is.True(EscapeAll.EscapeCharLF)
 	is.True(EscapeAll.EscapeCharCR)
+	is.True(EscapeAll.EscapeCharPipe)
+	is.True(EscapeAll.EscapeCharPercent)
 }
 
 func TestEscapeAll(t *testing.T) {
@@ -178,5 +437,119 @@ func TestEscapeAll(t *testing.T) {
 	is.True(EscapeAll.EscapeCharMinus)
 	is.True(EscapeAll.EscapeCharAt)
 	is.True(EscapeAll.EscapeCharTab)
+	is.True(EscapeAll.EscapeCharLF)
 	is.True(EscapeAll.EscapeCharCR)
+	is.True(EscapeAll.EscapeCharPipe)
+	is.True(EscapeAll.EscapeCharPercent)
+}
+
+func TestSafeWriterScratchBuffer(t *testing.T) {
+	is := assert.New(t)
+
+	var buff strings.Builder
+	w := NewSafeWriter(&buff, FullSafety)
+	w.MaxScratchSize = 16
+
+	is.NoError(w.Write([]string{"a", "b"}))
+	is.NotNil(w.buf)
+
+	is.NoError(w.Write([]string{strings.Repeat("x", 32)}))
+	is.Nil(w.buf)
+
+	is.NoError(w.Write([]string{"c"}))
+	w.Flush()
+	is.NoError(w.Error())
+	is.Equal("\"a\",\"b\"\n\""+strings.Repeat("x", 32)+"\"\n\"c\"\n", buff.String())
+
+	w = NewSafeWriter(io.Discard, FullSafety)
+	record := []string{"abc", "def", `"ghi"`}
+	is.Zero(testing.AllocsPerRun(100, func() { _ = w.Write(record) }))
+}
+
+type writesRecorder struct {
+	writes []string
+}
+
+func (r *writesRecorder) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func TestSafeWriterFlushThreshold(t *testing.T) {
+	is := assert.New(t)
+
+	out := &writesRecorder{}
+	w := NewSafeWriter(out, EscapeAll)
+	w.FlushThreshold = 4
+
+	is.NoError(w.Write([]string{"a"}))
+	is.Empty(out.writes)
+	is.NoError(w.Write([]string{"bc"}))
+	is.Equal([]string{"a\nbc\n"}, out.writes)
+	is.NoError(w.Write([]string{"d"}))
+	w.Flush()
+	is.Equal([]string{"a\nbc\n", "d\n"}, out.writes)
+
+	out = &writesRecorder{}
+	w = NewSafeWriter(out, EscapeAll)
+	w.FlushThreshold = 8192
+
+	record := []string{strings.Repeat("x", 1023)}
+	for i := 0; i < 10; i++ {
+		is.NoError(w.Write(record))
+	}
+	is.Len(out.writes, 1)
+	is.Len(out.writes[0], 8192)
+	w.Flush()
+	is.Len(out.writes, 2)
+	is.NoError(w.Error())
+}
+
+func TestSafeWriterWriteAllBatch(t *testing.T) {
+	is := assert.New(t)
+
+	out := &writesRecorder{}
+	w := NewSafeWriter(out, EscapeAll)
+	w.Columns = []ColumnOpts{{}, {Required: true}}
+
+	err := w.WriteAll([][]string{{"a", "=1"}, {"b", "2"}, {"c", ""}, {"d", "4"}})
+	is.ErrorIs(err, ErrRequiredField)
+	is.Equal(Stats{Records: 2, SanitizedFields: 1, RejectedRecords: 1}, w.Stats())
+	is.Empty(out.writes)
+
+	w.Flush()
+	is.Equal([]string{"a,\" =1\"\nb,2\n"}, out.writes)
+
+	is.NoError(w.WriteAll([][]string{{"e", "5"}}))
+	is.Equal([]string{"a,\" =1\"\nb,2\n", "e,5\n"}, out.writes)
+}
+
+var (
+	_ RecordWriter = (*SafeWriter)(nil)
+	_ RecordWriter = (*FixedWidthWriter)(nil)
+	_ RecordWriter = (*stdcsv.Writer)(nil)
+)
+
+func TestSafeWriterCanonical(t *testing.T) {
+	is := assert.New(t)
+
+	write := func(opts SafetyOpts, configure func(w *SafeWriter)) string {
+		var buff strings.Builder
+		w := NewSafeWriter(&buff, opts)
+		w.Canonical = true
+		configure(w)
+		is.NoError(w.WriteHeader([]string{"id", "phone", "note"}))
+		is.NoError(w.WriteCells([]Cell{StringCell("1"), StringCell("+33 6 12 34 56 78"), WithQuote(StringCell("a\r\nb"), QuoteAlways)}))
+		w.Flush()
+		is.NoError(w.Error())
+		return buff.String()
+	}
+
+	expected := "id,phone,note\n1,\" +33 6 12 34 56 78\",\"a\r\nb\"\n"
+	is.Equal(expected, write(EscapeAll, func(w *SafeWriter) {}))
+	is.Equal(expected, write(FullSafety, func(w *SafeWriter) { w.UseCRLF = true }))
+
+	is.Equal("id,phone,note\n1,\"+33 6 12 34 56 78\",\"a\r\nb\"\n", write(FullSafety, func(w *SafeWriter) {
+		w.Columns = []ColumnOpts{{}, PhoneNumberColumn}
+	}))
 }
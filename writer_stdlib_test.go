@@ -3,6 +3,7 @@ package csv
 import (
 	"bytes"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 )
@@ -107,3 +108,54 @@ func BenchmarkWrite(b *testing.B) {
 		w.Flush()
 	}
 }
+
+var benchmarkQuotedData = [][]string{
+	{`say "hello"`, "multi\nline", `"quoted"`, "a,b"},
+	{`say "hello"`, "multi\nline", `"quoted"`, "a,b"},
+	{`say "hello"`, "multi\nline", `"quoted"`, "a,b"},
+}
+
+func BenchmarkWriteQuoted(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		w := NewSafeWriter(&bytes.Buffer{}, FullSafety)
+		err := w.WriteAll(benchmarkQuotedData)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkRecords() [][]string {
+	records := make([][]string, 1000)
+	for i := range records {
+		records[i] = benchmarkWriteData[i%len(benchmarkWriteData)]
+	}
+	return records
+}
+
+func BenchmarkWriteAll(b *testing.B) {
+	records := benchmarkRecords()
+	w := NewSafeWriter(io.Discard, FullSafety)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := w.WriteAll(records); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteRows(b *testing.B) {
+	records := benchmarkRecords()
+	w := NewSafeWriter(io.Discard, FullSafety)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, record := range records {
+			if err := w.Write(record); err != nil {
+				b.Fatal(err)
+			}
+		}
+		w.Flush()
+	}
+}